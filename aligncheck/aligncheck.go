@@ -1,15 +1,49 @@
 // Package aligncheck provides lint integration for the aligncheck linter
 package aligncheck
 
-import "github.com/surullabs/lint/checkers"
+import (
+	"context"
+
+	"github.com/surullabs/lint/checkers"
+)
 
 // Check runs the aligncheck linter (https://github.com/opennota/check)
 type Check struct {
 }
 
+// Binary is the pinned version of aligncheck that is installed and run.
+var Binary = checkers.Binary{
+	Name:    "aligncheck",
+	Package: "github.com/opennota/check/cmd/aligncheck",
+	Module:  "github.com/opennota/check",
+	Version: "v0.0.0-20180911053232-0c771f5545ff",
+}
+
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "aligncheck",
+		InformationURI: "https://github.com/opennota/check",
+		Rules: []checkers.Rule{
+			{ID: "aligncheck", Description: "Suboptimal struct alignment", HelpURI: "https://github.com/opennota/check"},
+		},
+	})
+	checkers.RegisterChecker("aligncheck", Check{})
+}
+
 // Check runs aligncheck and returns any errors found.
 func (c Check) Check(pkgs ...string) error {
-	return checkers.Lint("aligncheck",
-		"github.com/opennota/check",
-		"github.com/opennota/check/cmd/aligncheck", pkgs)
+	return c.CheckContext(context.Background(), pkgs...)
 }
+
+// CheckContext runs aligncheck and returns any errors found. aligncheck is
+// killed if ctx is done before it completes.
+func (c Check) CheckContext(ctx context.Context, pkgs ...string) error {
+	return checkers.LintBinary(ctx, Binary, pkgs)
+}
+
+// Version returns the pinned version of aligncheck. It is part of the key used to
+// cache results.
+func (Check) Version() (string, error) { return Binary.String(), nil }
+
+// Binaries returns the pinned version of aligncheck. It is used by lint.Prefetch.
+func (Check) Binaries() []checkers.Binary { return []checkers.Binary{Binary} }
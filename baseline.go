@@ -0,0 +1,246 @@
+package lint
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+// BaselineEntry identifies an issue recorded in a Baseline. Entries do not
+// include line numbers so that they continue to match when code is moved.
+type BaselineEntry struct {
+	// Checker is the name of the checker that reported the issue.
+	Checker string `json:"checker"`
+	// File is the path of the file containing the issue relative to the
+	// directory of the baseline file.
+	File string `json:"file,omitempty"`
+	// Message is the issue message with any line and column numbers removed.
+	Message string `json:"message"`
+	// Context is a hash of the source lines surrounding the issue.
+	Context string `json:"context,omitempty"`
+}
+
+// Baseline holds known issues which are not reported. It allows linters to be
+// adopted for existing code by only reporting new issues.
+//
+// Create a baseline by writing the issues currently reported using WriteBaseline
+// and check the file in. Then use Apply to filter the issues reported by
+// Group.Check.
+//
+//    baseline, err := lint.ReadBaseline("lint_baseline.json")
+//    if err != nil {
+//    	t.Fatal(err)
+//    }
+//    if err = baseline.Apply(lint.Default.Check("./...")); err != nil {
+//    	t.Fatal(err)
+//    }
+type Baseline struct {
+	// Dir is the directory that File in each entry is relative to.
+	Dir string `json:"-"`
+	// Entries lists the issues in the baseline.
+	Entries []BaselineEntry `json:"entries"`
+}
+
+// ReadBaseline reads a Baseline written by WriteBaseline from path.
+func ReadBaseline(path string) (Baseline, error) {
+	var b Baseline
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return b, fmt.Errorf("failed to read baseline: %v", err)
+	}
+	if err = json.Unmarshal(data, &b); err != nil {
+		return b, fmt.Errorf("failed to parse baseline %s: %v", path, err)
+	}
+	if b.Dir, err = baselineDir(path); err != nil {
+		return b, err
+	}
+	return b, nil
+}
+
+// WriteBaseline writes a Baseline containing every issue in err to path.
+// See Issues for details on how issues are extracted from err.
+func WriteBaseline(path string, err error) error {
+	dir, derr := baselineDir(path)
+	if derr != nil {
+		return derr
+	}
+	b := Baseline{Dir: dir, Entries: []BaselineEntry{}}
+	sources := sourceLines{}
+	for _, issue := range Issues(err) {
+		b.Entries = append(b.Entries, b.entry(issue, sources))
+	}
+	sort.Slice(b.Entries, func(i, j int) bool {
+		ei, ej := b.Entries[i], b.Entries[j]
+		if ei.File != ej.File {
+			return ei.File < ej.File
+		}
+		if ei.Checker != ej.Checker {
+			return ei.Checker < ej.Checker
+		}
+		if ei.Message != ej.Message {
+			return ei.Message < ej.Message
+		}
+		return ei.Context < ej.Context
+	})
+	data, merr := json.MarshalIndent(b, "", "  ")
+	if merr != nil {
+		return fmt.Errorf("failed to encode baseline: %v", merr)
+	}
+	if werr := ioutil.WriteFile(path, append(data, '\n'), 0644); werr != nil {
+		return fmt.Errorf("failed to write baseline: %v", werr)
+	}
+	return nil
+}
+
+func baselineDir(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to find baseline directory: %v", err)
+	}
+	return filepath.Dir(abs), nil
+}
+
+// Filter removes issues in err that are part of the baseline. It returns any
+// entries in the baseline that did not match an issue, along with an error
+// containing the remaining issues, or nil if there are none. Checkers that
+// failed to run, as returned by Failures, are kept.
+//
+// An issue matches an entry if it was reported by the same checker for the same
+// file with the same message, ignoring line and column numbers. Issues whose
+// surrounding source is unchanged are matched first, so that identical messages
+// in a single file are told apart. Remaining issues match any remaining entry,
+// so edits to the surrounding code do not cause an issue to be reported.
+func (b Baseline) Filter(err error) ([]BaselineEntry, error) {
+	issues := Issues(err)
+	sources := sourceLines{}
+
+	type key struct{ checker, file, message string }
+	exact, fuzzy := map[BaselineEntry][]int{}, map[key][]int{}
+	for i, e := range b.Entries {
+		k := key{e.Checker, e.File, e.Message}
+		exact[e], fuzzy[k] = append(exact[e], i), append(fuzzy[k], i)
+	}
+	used := make([]bool, len(b.Entries))
+	match := func(candidates []int) bool {
+		for _, i := range candidates {
+			if !used[i] {
+				used[i] = true
+				return true
+			}
+		}
+		return false
+	}
+
+	entries := make([]BaselineEntry, len(issues))
+	matched := make([]bool, len(issues))
+	for i, issue := range issues {
+		entries[i] = b.entry(issue, sources)
+		matched[i] = match(exact[entries[i]])
+	}
+	var remaining []checkers.Issue
+	for i, e := range entries {
+		if !matched[i] && !match(fuzzy[key{e.Checker, e.File, e.Message}]) {
+			remaining = append(remaining, issues[i])
+		}
+	}
+	var unused []BaselineEntry
+	for i, e := range b.Entries {
+		if !used[i] {
+			unused = append(unused, e)
+		}
+	}
+	return unused, groupIssues(remaining, Failures(err))
+}
+
+// Apply is identical to Filter, except that an issue is also reported for each
+// unused baseline entry. This ensures that the baseline is pruned as issues are fixed.
+func (b Baseline) Apply(err error) error {
+	unused, filtered := b.Filter(err)
+	issues := Issues(filtered)
+	for _, e := range unused {
+		msg := fmt.Sprintf("unused baseline entry: %s: %s: %s", e.Checker, e.File, e.Message)
+		issue := checkers.Issue{Checker: "lint.Baseline", Severity: checkers.SeverityError, Message: msg, Raw: msg}
+		if e.File != "" {
+			issue.File = filepath.Join(b.Dir, filepath.FromSlash(e.File))
+		}
+		issues = append(issues, issue)
+	}
+	return groupIssues(issues, Failures(filtered))
+}
+
+// Fingerprint returns a hash identifying issue which, like a BaselineEntry,
+// does not change when the issue moves to a different line. File paths are
+// made relative to the current directory.
+func Fingerprint(issue checkers.Issue) string {
+	dir, err := os.Getwd()
+	if err != nil {
+		dir = ""
+	}
+	e := Baseline{Dir: dir}.entry(issue, sourceLines{})
+	sum := sha256.Sum256([]byte(strings.Join([]string{e.Checker, e.File, e.Message, e.Context}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+var positionRE = regexp.MustCompile(`(\.go):[0-9]+(:[0-9]+)?`)
+
+func (b Baseline) entry(issue checkers.Issue, sources sourceLines) BaselineEntry {
+	e := BaselineEntry{
+		Checker: issue.Checker,
+		Message: positionRE.ReplaceAllString(issue.Message, "$1"),
+	}
+	if issue.File == "" {
+		return e
+	}
+	abs, err := filepath.Abs(issue.File)
+	if err != nil {
+		abs = issue.File
+	}
+	e.File = filepath.ToSlash(abs)
+	if rel, rerr := filepath.Rel(b.Dir, abs); rerr == nil {
+		e.File = filepath.ToSlash(rel)
+	}
+	e.Context = sources.context(abs, issue.Line)
+	return e
+}
+
+// sourceLines caches the lines of source files read to compute issue context.
+type sourceLines map[string][]string
+
+// context returns a hash of the line before, at and after line in file. Leading
+// and trailing whitespace is ignored. An empty string is returned if the file
+// cannot be read.
+func (s sourceLines) context(file string, line int) string {
+	lines, ok := s[file]
+	if !ok {
+		if f, err := os.Open(file); err == nil {
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				lines = append(lines, strings.TrimSpace(scanner.Text()))
+			}
+			_ = f.Close()
+		}
+		s[file] = lines
+	}
+	if line <= 0 || line > len(lines) {
+		return ""
+	}
+	start, end := line-2, line+1
+	if start < 0 {
+		start = 0
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(lines[start:end], "\n")))
+	return hex.EncodeToString(sum[:8])
+}
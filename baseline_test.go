@@ -0,0 +1,88 @@
+package lint_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+)
+
+func TestBaseline(t *testing.T) {
+	dir := t.TempDir()
+
+	src := filepath.Join(dir, "src.go")
+	write := func(content string) {
+		if werr := ioutil.WriteFile(src, []byte(content), 0644); werr != nil {
+			t.Fatal(werr)
+		}
+	}
+	write("package src\n\nfunc a() {\n\tf()\n}\n\nfunc b() {\n\tg()\n}\n")
+	issues := func(errs ...string) error {
+		return lint.Group{checkFn(func(...string) error { return checkers.Error(errs...) })}.Check()
+	}
+
+	path := filepath.Join(dir, "baseline.json")
+	err := lint.WriteBaseline(path, issues(
+		src+":4:2: error not checked",
+		src+":8:2: error not checked",
+		"found 2 clones: at somefile.go:10",
+	))
+	assert(t, err == nil, fmt.Sprintf("%v", err))
+
+	baseline, err := lint.ReadBaseline(path)
+	assert(t, err == nil, fmt.Sprintf("%v", err))
+	assert(t, baseline.Dir == dir && len(baseline.Entries) == 3, fmt.Sprintf("%#v", baseline))
+	assert(t, baseline.Entries[0] == lint.BaselineEntry{
+		Checker: "lint_test.checkFn", Message: "found 2 clones: at somefile.go",
+	}, fmt.Sprintf("%#v", baseline.Entries[0]))
+	assert(t, baseline.Entries[1].File == "src.go" && baseline.Entries[1].Context != "",
+		fmt.Sprintf("%#v", baseline.Entries[1]))
+
+	// Lines shifted and one issue added
+	write("package src\n\n// a is a function\nfunc a() {\n\tf()\n}\n\nfunc b() {\n\tg()\n\th()\n}\n")
+	unused, err := baseline.Filter(issues(
+		src+":5:2: error not checked",
+		src+":9:2: error not checked",
+		src+":10:2: error not checked",
+		"found 2 clones: at somefile.go:12",
+	))
+	assert(t, len(unused) == 0, fmt.Sprintf("%v", unused))
+	assert(t, err != nil && err.Error() == "lint_test.checkFn: "+src+":10:2: error not checked",
+		fmt.Sprintf("%v", err))
+
+	// Code edited around a baselined issue
+	write("package src\n\nfunc a() {\n\tf(1)\n}\n")
+	unused, err = baseline.Filter(issues(src + ":4:2: error not checked"))
+	assert(t, err == nil, fmt.Sprintf("%v", err))
+	assert(t, len(unused) == 2 && unused[0].Message == "found 2 clones: at somefile.go" &&
+		unused[1].Message == "error not checked", fmt.Sprintf("%v", unused))
+
+	// Unused entries are reported by Apply
+	err = baseline.Apply(nil)
+	expected := []string{
+		"lint.Baseline: unused baseline entry: lint_test.checkFn: : found 2 clones: at somefile.go",
+		"lint.Baseline: unused baseline entry: lint_test.checkFn: src.go: error not checked",
+		"lint.Baseline: unused baseline entry: lint_test.checkFn: src.go: error not checked",
+	}
+	assert(t, err != nil && reflect.DeepEqual(err.(interface {
+		Errors() []string
+	}).Errors(), expected), fmt.Sprintf("%v", err))
+	assert(t, lint.Issues(err)[1].File == src, fmt.Sprintf("%#v", lint.Issues(err)))
+
+	// Failures are kept, even if their output is in the baseline
+	failing := lint.Group{checkFn(func(...string) error {
+		return &checkers.ToolError{Tool: "fail", Err: checkers.Error(src + ":4:2: error not checked")}
+	})}.Check()
+	_, err = baseline.Filter(failing)
+	failures := lint.Failures(err)
+	assert(t, len(failures) == 1 && failures[0].Checker == "lint_test.checkFn", fmt.Sprintf("%#v", failures))
+	err = baseline.Apply(failing)
+	assert(t, len(lint.Failures(err)) == 1, fmt.Sprintf("%v", err))
+
+	_, err = lint.ReadBaseline(filepath.Join(dir, "missing.json"))
+	assert(t, err != nil, "expected an error reading a missing baseline")
+}
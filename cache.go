@@ -0,0 +1,419 @@
+package lint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+// Versioner is implemented by checkers that can report the version of the
+// linter they run. The version is part of the key used to cache issues.
+type Versioner interface {
+	Version() (string, error)
+}
+
+// Cache stores the issues reported by checkers for each package in a
+// directory, similar to the go build cache. It is safe for concurrent use.
+type Cache struct {
+	// Dir is the directory holding cached issues.
+	Dir string
+
+	hits, misses int64
+}
+
+// CacheStats holds the number of packages whose issues were found in a Cache
+// and the number that had to be checked.
+type CacheStats struct {
+	Hits, Misses int
+}
+
+// DefaultCacheDir returns the directory named by the LINT_CACHE environment
+// variable, or a lint directory in the user cache directory if it is not set.
+func DefaultCacheDir() (string, error) {
+	if dir := os.Getenv("LINT_CACHE"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory: %v", err)
+	}
+	return filepath.Join(dir, "surullabs-lint"), nil
+}
+
+// NewCache returns a Cache storing issues in dir, or in DefaultCacheDir if dir
+// is empty.
+func NewCache(dir string) (*Cache, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultCacheDir(); err != nil {
+			return nil, err
+		}
+	}
+	return &Cache{Dir: dir}, nil
+}
+
+// Stats returns the number of cache hits and misses so far.
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{Hits: int(atomic.LoadInt64(&c.hits)), Misses: int(atomic.LoadInt64(&c.misses))}
+}
+
+func (c *Cache) file(key string) string {
+	return filepath.Join(c.Dir, key[:2], key+"-issues.json")
+}
+
+func (c *Cache) get(key string) ([]checkers.Issue, bool) {
+	data, err := ioutil.ReadFile(c.file(key))
+	if err != nil {
+		return nil, false
+	}
+	var issues []checkers.Issue
+	if err = json.Unmarshal(data, &issues); err != nil {
+		return nil, false
+	}
+	return issues, true
+}
+
+// put stores issues for key. The file is written to a temporary file and
+// renamed so that concurrent readers never see partial results. Failures are
+// ignored since they only cause a later cache miss.
+func (c *Cache) put(key string, issues []checkers.Issue) {
+	data, err := json.Marshal(issues)
+	if err != nil {
+		return
+	}
+	file := c.file(key)
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(file), "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// Cached is a ContextChecker that reuses the issues Checker reported for
+// packages that did not change since they were last checked.
+//
+// Issues are cached for each package, keyed by the type and options of
+// Checker, with analyzers identified by name, the version of its linter if it
+// implements Versioner, the Go version
+// and a hash of the files in the package and its imports, as returned by
+// checkers.HashPackages. The hashes are computed once for all the Cached
+// checkers in a Group. Only the packages that are not in the cache are
+// checked. Results are not cached if Checker reports an issue without a file
+// or outside the packages checked, such as an error running the linter.
+//
+// Checkers which report issues across packages, such as dupl, should not be
+// cached.
+type Cached struct {
+	Checker Checker
+	Cache   *Cache
+}
+
+// Check runs Checker for packages that are not in the Cache.
+func (c Cached) Check(pkgs ...string) error {
+	return c.CheckContext(context.Background(), pkgs...)
+}
+
+// cachedPackage is a package checked by Cached.
+type cachedPackage struct {
+	path, dir, key string
+	issues         []checkers.Issue
+}
+
+// CheckContext is identical to Check, except that ctx is passed to Checker if
+// it implements ContextChecker.
+func (c Cached) CheckContext(ctx context.Context, pkgs ...string) error {
+	prefix, err := c.keyPrefix()
+	if err != nil {
+		return c.check(ctx, pkgs)
+	}
+	hashes, err := packageHashes(ctx, pkgs)
+	if err != nil {
+		return c.check(ctx, pkgs)
+	}
+	all, err := cachedPackages(prefix, pkgs, hashes)
+	if err != nil {
+		return c.check(ctx, pkgs)
+	}
+	var missed []*cachedPackage
+	for _, p := range all {
+		var ok bool
+		if p.issues, ok = c.Cache.get(p.key); ok {
+			atomic.AddInt64(&c.Cache.hits, 1)
+		} else {
+			atomic.AddInt64(&c.Cache.misses, 1)
+			missed = append(missed, p)
+		}
+	}
+	if len(missed) == 0 {
+		return checkers.Issues(joinIssues(all, nil)...)
+	}
+	paths := pkgs
+	if len(missed) < len(all) {
+		paths = make([]string, len(missed))
+		for i, p := range missed {
+			paths[i] = p.path
+		}
+	}
+	err = check(ctx, c.Checker, paths)
+	if failed(err) {
+		return err
+	}
+	issues := namedIssues(c.Checker, err)
+	// Results for a cancelled check are incomplete and must not be cached.
+	unassigned := assignIssues(missed, issues)
+	if len(unassigned) == 0 && ctx.Err() == nil {
+		for _, p := range missed {
+			c.Cache.put(p.key, p.issues)
+		}
+	}
+	return checkers.Issues(joinIssues(all, unassigned)...)
+}
+
+func (c Cached) check(ctx context.Context, pkgs []string) error {
+	err := check(ctx, c.Checker, pkgs)
+	if failed(err) {
+		return err
+	}
+	return checkers.Issues(namedIssues(c.Checker, err)...)
+}
+
+// keyPrefix returns the part of the cache key shared by all packages.
+func (c Cached) keyPrefix() (string, error) {
+	version := ""
+	if v, ok := c.Checker.(Versioner); ok {
+		var err error
+		if version, err = v.Version(); err != nil {
+			return "", err
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	var options strings.Builder
+	writeOptions(&options, reflect.ValueOf(c.Checker), map[uintptr]bool{})
+	return fmt.Sprintf("lint cache v2\n%s\n%s\n%s\n%s\n%s\n",
+		runtime.Version(), CheckerName(c.Checker), options.String(), version, wd), nil
+}
+
+// analyzerType is the type of analyzers run by checkers such as govet.
+var analyzerType = reflect.TypeOf(&analysis.Analyzer{})
+
+// writeOptions writes a description of v that does not change between runs of
+// a process, unlike %#v which includes the addresses of pointers. Analyzers
+// are described by their name and functions are ignored.
+func writeOptions(b *strings.Builder, v reflect.Value, seen map[uintptr]bool) {
+	if !v.IsValid() {
+		b.WriteString("nil")
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			b.WriteString("nil")
+		} else if v.Type() == analyzerType {
+			b.WriteString("analyzer " + v.Elem().FieldByName("Name").String())
+		} else if seen[v.Pointer()] {
+			b.WriteString("cycle")
+		} else {
+			seen[v.Pointer()] = true
+			b.WriteString("&")
+			writeOptions(b, v.Elem(), seen)
+			delete(seen, v.Pointer())
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		writeOptions(b, v.Elem(), seen)
+	case reflect.Struct:
+		b.WriteString(v.Type().String() + "{")
+		for i := 0; i < v.NumField(); i++ {
+			b.WriteString(v.Type().Field(i).Name + ":")
+			writeOptions(b, v.Field(i), seen)
+			b.WriteString(" ")
+		}
+		b.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		b.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			writeOptions(b, v.Index(i), seen)
+			b.WriteString(" ")
+		}
+		b.WriteString("]")
+	case reflect.Map:
+		entries := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			var e strings.Builder
+			writeOptions(&e, k, seen)
+			e.WriteString(":")
+			writeOptions(&e, v.MapIndex(k), seen)
+			entries = append(entries, e.String())
+		}
+		sort.Strings(entries)
+		b.WriteString("map[" + strings.Join(entries, " ") + "]")
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	default:
+		// Functions, channels and unsafe pointers cannot be described.
+		b.WriteString(v.Type().String())
+	}
+}
+
+// hashesKey is the context key of the sharedHashes used by a Group check.
+type hashesKey struct{}
+
+// sharedHashes holds the package hashes computed by the Cached checkers in a
+// Group check, so that each set of packages is hashed once however many
+// checkers are cached.
+type sharedHashes struct {
+	mu      sync.Mutex
+	entries map[string]*hashesEntry
+}
+
+type hashesEntry struct {
+	once   sync.Once
+	hashes map[string]string
+	err    error
+}
+
+func (s *sharedHashes) get(pkgs []string) (map[string]string, error) {
+	key := strings.Join(pkgs, "\n")
+	s.mu.Lock()
+	if s.entries == nil {
+		s.entries = map[string]*hashesEntry{}
+	}
+	e := s.entries[key]
+	if e == nil {
+		e = &hashesEntry{}
+		s.entries[key] = e
+	}
+	s.mu.Unlock()
+	e.once.Do(func() { e.hashes, e.err = checkers.HashPackages(pkgs...) })
+	return e.hashes, e.err
+}
+
+// withSharedHashes returns a context in which Cached checkers share package
+// hashes.
+func withSharedHashes(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hashesKey{}, &sharedHashes{})
+}
+
+// packageHashes returns the hashes of pkgs, reusing those computed for ctx by
+// other checkers if possible.
+func packageHashes(ctx context.Context, pkgs []string) (map[string]string, error) {
+	if s, ok := ctx.Value(hashesKey{}).(*sharedHashes); ok {
+		return s.get(pkgs)
+	}
+	return checkers.HashPackages(pkgs...)
+}
+
+// cachedPackages expands pkgs into the packages they match, along with their
+// cache keys computed from hashes, as returned by checkers.HashPackages.
+func cachedPackages(prefix string, pkgs []string, hashes map[string]string) ([]*cachedPackage, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	var res []*cachedPackage
+	seen := map[string]bool{}
+	paths := canonicalPaths{}
+	for _, pkg := range pkgs {
+		p, err := checkers.Load(pkg)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range p.Dirs {
+			hash, ok := hashes[dir]
+			if !ok {
+				return nil, fmt.Errorf("no hash for %s", dir)
+			}
+			if dir = paths.get(dir); seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			path, err := relativePackage(wd, dir)
+			if err != nil {
+				return nil, err
+			}
+			sum := sha256.Sum256([]byte(prefix + path + "\n" + hash))
+			res = append(res, &cachedPackage{path: path, dir: dir, key: hex.EncodeToString(sum[:])})
+		}
+	}
+	return res, nil
+}
+
+// assignIssues adds each issue to the package containing its file and
+// returns the issues that are not part of any package in pkgs.
+func assignIssues(pkgs []*cachedPackage, issues []checkers.Issue) []checkers.Issue {
+	byDir := map[string]*cachedPackage{}
+	for _, p := range pkgs {
+		p.issues = []checkers.Issue{}
+		byDir[p.dir] = p
+	}
+	paths := canonicalPaths{}
+	var unassigned []checkers.Issue
+	for _, issue := range issues {
+		p := byDir[filepath.Dir(paths.get(issue.File))]
+		if issue.File == "" || p == nil {
+			unassigned = append(unassigned, issue)
+			continue
+		}
+		p.issues = append(p.issues, issue)
+	}
+	return unassigned
+}
+
+func joinIssues(pkgs []*cachedPackage, unassigned []checkers.Issue) []checkers.Issue {
+	var res []checkers.Issue
+	for _, p := range pkgs {
+		res = append(res, p.issues...)
+	}
+	return append(res, unassigned...)
+}
+
+// Cached returns a copy of g with each Checker wrapped in a Cached using cache.
+func (g Group) Cached(cache *Cache) Group {
+	wrapped := make([]Checker, len(g))
+	for i, c := range g {
+		wrapped[i] = Cached{Checker: c, Cache: cache}
+	}
+	return Group(wrapped)
+}
@@ -0,0 +1,217 @@
+package lint_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+	"github.com/surullabs/lint/goanalysis"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOWORK", "")
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/m\n")
+	writeFile(t, filepath.Join(dir, "a", "a.go"), "package a\n\nimport _ \"example.com/m/b\"\n")
+	writeFile(t, filepath.Join(dir, "b", "b.go"), "package b\n")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+	defer checkers.Unload("./...")
+
+	var checked [][]string
+	noFile := false
+	// The checker reports an issue for each package it checks.
+	checker := ctxCheckFn(func(ctx context.Context, pkgs ...string) error {
+		checked = append(checked, pkgs)
+		var issues []checkers.Issue
+		for _, pkg := range pkgs {
+			p, lerr := checkers.Load(pkg)
+			if lerr != nil {
+				return lerr
+			}
+			for _, d := range p.Dirs {
+				name := filepath.Base(d)
+				issues = append(issues, checkers.ParseIssue(fmt.Sprintf("%s/%s.go:1: issue", name, name)))
+			}
+		}
+		if noFile {
+			issues = append(issues, checkers.ParseIssue("linter failed"))
+		}
+		return checkers.Issues(issues...)
+	})
+	cache, err := lint.NewCache(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := lint.Group{checker}.Cached(cache)
+	expected := []string{"lint_test.ctxCheckFn: a/a.go:1: issue", "lint_test.ctxCheckFn: b/b.go:1: issue"}
+	for i, test := range []struct {
+		change  string
+		noFile  bool
+		checked [][]string
+		stats   lint.CacheStats
+	}{
+		{checked: [][]string{{"./..."}}, stats: lint.CacheStats{Misses: 2}},
+		{stats: lint.CacheStats{Hits: 2, Misses: 2}},
+		// a imports b, so changing b invalidates both.
+		{change: "b/b.go", checked: [][]string{{"./..."}}, stats: lint.CacheStats{Hits: 2, Misses: 4}},
+		{change: "a/a.go", checked: [][]string{{"./a"}}, stats: lint.CacheStats{Hits: 3, Misses: 5}},
+		// Results are not cached if an issue cannot be assigned to a package.
+		{change: "a/a.go", noFile: true, checked: [][]string{{"./a"}}, stats: lint.CacheStats{Hits: 4, Misses: 6}},
+		{checked: [][]string{{"./a"}}, stats: lint.CacheStats{Hits: 5, Misses: 7}},
+	} {
+		if test.change != "" {
+			file := filepath.Join(dir, test.change)
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeFile(t, file, string(data)+"\n// changed\n")
+		}
+		checked, noFile = nil, test.noFile
+		var res []string
+		for _, issue := range lint.Issues(g.Check("./...")) {
+			res = append(res, issue.String())
+		}
+		exp := expected
+		if test.noFile {
+			exp = append(exp[:2:2], "lint_test.ctxCheckFn: linter failed")
+		}
+		assert(t, reflect.DeepEqual(res, exp), fmt.Sprintf("%d: unexpected issues %v", i, res))
+		assert(t, reflect.DeepEqual(checked, test.checked), fmt.Sprintf("%d: unexpected packages checked %v", i, checked))
+		assert(t, cache.Stats() == test.stats, fmt.Sprintf("%d: unexpected stats %+v", i, cache.Stats()))
+	}
+}
+
+func TestCacheAnalyzers(t *testing.T) {
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOWORK", "")
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/m\n")
+	writeFile(t, filepath.Join(dir, "a", "a.go"), "package a\n")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+	defer checkers.Unload("./...")
+
+	cache, err := lint.NewCache(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Analyzers are created for each check, as done by rules.Check.
+	check := func(name string) {
+		a := &analysis.Analyzer{Name: name, Doc: "reports nothing", Run: func(*analysis.Pass) (interface{}, error) { return nil, nil }}
+		if err := (lint.Group{goanalysis.Check{Analyzers: []*analysis.Analyzer{a}}}.Cached(cache)).Check("./..."); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, test := range []struct {
+		name  string
+		stats lint.CacheStats
+	}{
+		{"first", lint.CacheStats{Misses: 1}},
+		{"first", lint.CacheStats{Hits: 1, Misses: 1}},
+		{"second", lint.CacheStats{Hits: 1, Misses: 2}},
+	} {
+		check(test.name)
+		assert(t, cache.Stats() == test.stats, fmt.Sprintf("%d: unexpected stats %+v", i, cache.Stats()))
+	}
+}
+
+func TestCacheHashes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("go is wrapped using a shell script")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOWORK", "")
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/m\n")
+	writeFile(t, filepath.Join(dir, "a", "a.go"), "package a\n\nimport _ \"example.com/m/b\"\n")
+	writeFile(t, filepath.Join(dir, "b", "b.go"), "package b\n")
+	// go is wrapped to log the commands run.
+	log := filepath.Join(dir, "go.log")
+	writeFile(t, filepath.Join(dir, "bin", "go"), fmt.Sprintf("#!/bin/sh\necho \"$@\" >> %q\nexec %q \"$@\"\n", log, goBin))
+	if err = os.Chmod(filepath.Join(dir, "bin", "go"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", filepath.Join(dir, "bin")+string(filepath.ListSeparator)+os.Getenv("PATH"))
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+	defer checkers.Unload("./...")
+
+	cache, err := lint.NewCache(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := lint.Group{
+		checkFn(func(...string) error { return nil }),
+		ctxCheckFn(func(context.Context, ...string) error { return nil }),
+	}.Cached(cache)
+	if err = g.CheckParallel(0, "./..."); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The imports of all packages are listed once for both checkers.
+	assert(t, strings.Count(string(data), "list -e -json -deps ") == 1, string(data))
+	assert(t, cache.Stats() == lint.CacheStats{Misses: 4}, fmt.Sprintf("unexpected stats %+v", cache.Stats()))
+}
@@ -0,0 +1,125 @@
+package lint
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+// Changes is an IssueSkipper that skips issues which are not on lines added or
+// modified in a git repository. Use it to only report issues in new code, for
+// instance when checking a pull request.
+//
+// Issues without file information and issues for a whole file (with no line
+// number) in a changed file are not skipped.
+type Changes struct {
+	// Root is the root directory of the git repository.
+	Root string
+	// Lines holds the lines added or modified, keyed by the slash separated path
+	// of each file relative to Root.
+	Lines map[string]map[int]bool
+}
+
+// GitChanges returns the Changes between base and HEAD, as reported by
+//
+//    git diff base...HEAD
+//
+// for the git repository containing the current directory. This includes all
+// changes made on the current branch since it diverged from base.
+func GitChanges(base string) (Changes, error) {
+	c := Changes{Lines: map[string]map[int]bool{}}
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return c, fmt.Errorf("failed to find git repository: %v", err)
+	}
+	root := strings.TrimSpace(string(out))
+	if c.Root, err = filepath.EvalSymlinks(root); err != nil {
+		return c, fmt.Errorf("failed to resolve git repository %s: %v", root, err)
+	}
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "diff", "--no-color", "--no-ext-diff",
+		"--no-renames", "--unified=0", "--no-prefix", base+"...HEAD")
+	cmd.Dir = c.Root
+	res, err := checkers.Exec(cmd)
+	if err != nil {
+		return c, fmt.Errorf("git diff %s...HEAD failed: %v: %s", base, err, res.Stderr)
+	}
+	if err = c.parseDiff(res.Stdout); err != nil {
+		return c, fmt.Errorf("failed to parse git diff: %v", err)
+	}
+	return c, nil
+}
+
+var hunkRE = regexp.MustCompile(`^@@ -[0-9,]+ \+([0-9]+)(?:,([0-9]+))? @@`)
+
+func (c Changes) parseDiff(diff string) error {
+	var lines map[int]bool
+	// Added lines can start with +++, so file names are only read from the
+	// header preceding the hunks of each file.
+	header := false
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			header = true
+		case header && strings.HasPrefix(line, "+++ "):
+			// git appends a tab to file names containing spaces
+			file := strings.TrimRight(strings.TrimPrefix(line, "+++ "), "\t")
+			if unquoted, err := strconv.Unquote(file); err == nil {
+				file = unquoted
+			}
+			lines = nil
+			if file != "/dev/null" {
+				lines = map[int]bool{}
+				c.Lines[file] = lines
+			}
+		case strings.HasPrefix(line, "@@ "):
+			header = false
+			m := hunkRE.FindStringSubmatch(line)
+			if m == nil {
+				return fmt.Errorf("invalid hunk header: %s", line)
+			}
+			if lines == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+			for i := start; i < start+count; i++ {
+				lines[i] = true
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// SkipIssue returns true if issue is for a file or line that did not change.
+func (c Changes) SkipIssue(issue checkers.Issue) bool {
+	if issue.File == "" {
+		return false
+	}
+	rel, err := filepath.Rel(c.Root, canonicalPaths{}.get(issue.File))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	lines, changed := c.Lines[filepath.ToSlash(rel)]
+	if !changed {
+		return true
+	}
+	return issue.Line > 0 && !lines[issue.Line]
+}
+
+// Skip parses err using checkers.ParseIssue, which removes any checker prefix
+// added by Group.Check, and returns the result of SkipIssue.
+func (c Changes) Skip(err string) bool {
+	return c.SkipIssue(checkers.ParseIssue(err))
+}
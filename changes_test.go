@@ -0,0 +1,89 @@
+package lint_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+	"github.com/surullabs/lint/testutil"
+)
+
+func git(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=lint", "-c", "user.email=lint@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v: %s", args, err, out)
+	}
+}
+
+func TestGitChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if merr := os.MkdirAll(filepath.Dir(path), 0755); merr != nil {
+			t.Fatal(merr)
+		}
+		if werr := ioutil.WriteFile(path, []byte(content), 0644); werr != nil {
+			t.Fatal(werr)
+		}
+	}
+
+	git(t, dir, "init", "-q")
+	write("a.go", "package a\n\nfunc a() {\n}\n")
+	write("old.go", "package a\n")
+	write("pkg/b.go", "package pkg\n\nfunc b() {\n}\n")
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "base")
+	git(t, dir, "tag", "base")
+
+	write("a.go", "package a\n\nfunc a() {\n\tf()\n}\n")
+	write("new file.go", "package a\n\nfunc c() {\n}\n")
+	if err := os.Remove(filepath.Join(dir, "old.go")); err != nil {
+		t.Fatal(err)
+	}
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "change")
+	// Uncommitted changes are ignored
+	write("pkg/b.go", "package pkg\n\nfunc b() {\n\tg()\n}\n")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(filepath.Join(dir, "pkg")); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	changes, err := lint.GitChanges("base")
+	assert(t, err == nil, fmt.Sprintf("%v", err))
+	assert(t, len(changes.Lines) == 2 && changes.Lines["a.go"][4] && len(changes.Lines["a.go"]) == 1 &&
+		len(changes.Lines["new file.go"]) == 4, fmt.Sprintf("%v", changes.Lines))
+
+	testutil.TestSkips(t, []testutil.SkipTest{
+		{S: changes, Line: "no location", Skip: false},
+		{S: changes, Line: "../a.go:4:2: error not checked", Skip: false},
+		{S: changes, Line: "govet.Check: ../a.go:4: unreachable code", Skip: false},
+		{S: changes, Line: "../a.go:3:1: exported function", Skip: true},
+		{S: changes, Line: "b.go:4:2: error not checked", Skip: true},
+		{S: changes, Line: filepath.Join(dir, "new file.go") + ":3:1: unused", Skip: false},
+		{S: changes, Line: "/elsewhere/file.go:3:1: unused", Skip: false},
+	})
+	assert(t, !changes.SkipIssue(checkers.Issue{File: "../a.go"}), "file level issue skipped")
+
+	err = lint.Skip(lint.Group{checkFn(func(...string) error {
+		return checkers.Error("../a.go:3:1: old issue", "../a.go:4:2: new issue")
+	})}.Check(), changes)
+	assert(t, err != nil && err.Error() == "lint_test.checkFn: ../a.go:4:2: new issue", fmt.Sprintf("%v", err))
+
+	_, err = lint.GitChanges("missing-revision")
+	assert(t, err != nil, "expected an error for a missing revision")
+}
@@ -2,11 +2,13 @@
 package checkers
 
 import (
+	"context"
 	"fmt"
 	"go/build"
 	"io/ioutil"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"syscall"
 
@@ -37,14 +39,6 @@ type errorList []string
 func (e errorList) Errors() []string { return []string(e) }
 func (e errorList) Error() string    { return strings.Join(e, "\n") }
 
-func packageDir(path string) (string, error) {
-	pkg, err := build.Import(path, ".", build.FindOnly)
-	if err != nil {
-		return "", fmt.Errorf("import failed: %s: %v", path, err)
-	}
-	return pkg.Dir, nil
-}
-
 // FindBin returns bin if it exists in the path. If not it checks
 // go bin directories ($GOROOT/bin and $GOPATH/bin) and returns that if it exists.
 // If neither exist it returns an error.
@@ -62,23 +56,105 @@ func FindBin(bin string) (string, error) {
 	return "", fmt.Errorf("failed to find binary: %v", bin)
 }
 
+// InstallError is returned when a linter cannot be installed.
+type InstallError struct {
+	// Bin is the name of the linter binary.
+	Bin string
+	// Err describes why the install failed.
+	Err error
+}
+
+func (e *InstallError) Error() string { return e.Err.Error() }
+
+// ToolError is returned when a linter fails to check packages, for example
+// because they cannot be loaded, the linter is misconfigured or it crashes. It
+// is distinct from the issues reported by a linter that ran successfully.
+type ToolError struct {
+	// Tool is the name of the linter.
+	Tool string
+	// Err describes the failure, along with any output of the linter.
+	Err error
+}
+
+func (e *ToolError) Error() string { return e.Err.Error() }
+
+// Errors returns the lines of output in Err, if it implements Errors, or the
+// error string otherwise. Any issues in the output can therefore be parsed as
+// described in lint.Issues.
+func (e *ToolError) Errors() []string {
+	if errs, ok := e.Err.(interface {
+		Errors() []string
+	}); ok {
+		return errs.Errors()
+	}
+	return []string{e.Err.Error()}
+}
+
 // InstallMissing runs go get getPath and then go get importPath
 // if bin cannot be found in the directories contained in the PATH environment variable.
-// It returns the path to the installed binary on success.
+// It returns the path to the installed binary on success and an *InstallError
+// on failure. Use Binary to install a pinned version instead.
+//
+// Concurrent installs of bin are serialized using a lock file in the
+// temporary directory, so that processes waiting for the lock reuse the binary
+// installed by the first one. The binary is built in a temporary directory and
+// renamed into $GOBIN, or the bin directory of the first GOPATH entry.
+//
+// If Offline is true, nothing is downloaded and an error listing the commands
+// needed to install bin is returned instead.
 func InstallMissing(bin, getPath, importPath string) (string, error) {
 	if b, err := FindBin(bin); err == nil {
 		return b, nil
 	}
-	if data, err := exec.Command("go", "get", getPath).CombinedOutput(); err != nil {
-		return "", fmt.Errorf("failed to get %s: %v: %s", importPath, err, string(data))
+	if Offline {
+		return "", &InstallError{bin, fmt.Errorf(`%s is not in PATH and downloads are disabled in offline mode. Install it using
+	go get %s
+	go install %s
+on a machine with network access and copy it to a directory in PATH`, bin, getPath, importPath)}
 	}
-
-	if data, err := exec.Command("go", "install", importPath).CombinedOutput(); err != nil {
-		return "", fmt.Errorf("failed to install %s: %v: %s", importPath, err, string(data))
+	unlock, err := lockFile(filepath.Join(os.TempDir(), "surullabs-lint-"+bin+".lock"))
+	if err != nil {
+		return "", &InstallError{bin, err}
 	}
+	defer unlock()
+	// Another process may have installed bin while this one waited for the lock.
 	b, err := FindBin(bin)
+	if err == nil {
+		return b, nil
+	}
+	gobin := goEnv("GOBIN")
+	if gobin == "" {
+		gobin = filepath.Join(filepath.SplitList(build.Default.GOPATH)[0], "bin")
+	}
+	if err = os.MkdirAll(gobin, 0755); err != nil {
+		return "", &InstallError{bin, fmt.Errorf("failed to create %s: %v", gobin, err)}
+	}
+	tmp, err := ioutil.TempDir(gobin, "tmp-")
+	if err != nil {
+		return "", &InstallError{bin, fmt.Errorf("failed to create temporary directory: %v", err)}
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+	env := append(os.Environ(), "GOBIN="+tmp)
+	get := exec.Command("go", "get", getPath)
+	get.Env = env
+	data, err := get.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("failed to lookup %v after install: %v", bin, err)
+		return "", &InstallError{bin, fmt.Errorf("failed to get %s: %v: %s", importPath, err, string(data))}
+	}
+	install := exec.Command("go", "install", importPath)
+	install.Env = env
+	if data, err = install.CombinedOutput(); err != nil {
+		return "", &InstallError{bin, fmt.Errorf("failed to install %s: %v: %s", importPath, err, string(data))}
+	}
+	name := bin
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	if err = os.Rename(filepath.Join(tmp, name), filepath.Join(gobin, name)); err != nil {
+		return "", &InstallError{bin, fmt.Errorf("failed to install %s: %v", importPath, err)}
+	}
+	if b, err = FindBin(bin); err != nil {
+		return "", &InstallError{bin, fmt.Errorf("failed to lookup %v after install: %v", bin, err)}
 	}
 	return b, nil
 }
@@ -104,6 +180,13 @@ func (e *ExecErrors) Add(r ExecResult) {
 //
 // If getPath is empty, installPath is used for go get.
 func Lint(bin, getPath, installPath string, pkgs []string, args ...string) error {
+	return LintContext(context.Background(), bin, getPath, installPath, pkgs, args...)
+}
+
+// LintContext is identical to Lint, except that the linter is killed if ctx
+// is done before it completes. An error naming the package being checked is then
+// reported and any remaining packages are not checked.
+func LintContext(ctx context.Context, bin, getPath, installPath string, pkgs []string, args ...string) error {
 	if getPath == "" {
 		getPath = installPath
 	}
@@ -111,18 +194,64 @@ func Lint(bin, getPath, installPath string, pkgs []string, args ...string) error
 	if err != nil {
 		return err
 	}
+	return lint(ctx, bin, b, pkgs, args)
+}
+
+// LintBinary is identical to LintContext, except that the linter is installed
+// using b.Install.
+func LintBinary(ctx context.Context, b Binary, pkgs []string, args ...string) error {
+	path, err := b.Install()
+	if err != nil {
+		return err
+	}
+	return lint(ctx, b.Name, path, pkgs, args)
+}
+
+// lint runs the linter bin, installed at path, for each package in pkgs.
+//
+// A *ToolError holding all output is returned if a package cannot be loaded,
+// the linter cannot be run or it prints a line that is not an issue with a
+// location, such as a crash or a failure to type check a package.
+func lint(ctx context.Context, bin, path string, pkgs []string, args []string) error {
 	errs := &ExecErrors{}
+	failed := false
 	for _, pkg := range pkgs {
 		p, perr := Load(pkg)
 		if perr != nil {
-			return fmt.Errorf("failed to load pkg info: %s: %v", pkg, perr)
+			return &ToolError{Tool: bin, Err: fmt.Errorf("failed to load pkg info: %s: %v", pkg, perr)}
 		}
-		result, _ := Exec(exec.Command(b, append(args, p.Path)...))
+		result, err := Exec(Command(ctx, path, append(args, p.Path)...))
+		if ctx.Err() != nil {
+			*errs = append(*errs, fmt.Sprintf("%s: stopped checking %s: %v", bin, pkg, ctx.Err()))
+			break
+		}
+		if err != nil && result.Code == -1 {
+			*errs = append(*errs, fmt.Sprintf("%s: failed to check %s: %v", bin, pkg, err))
+			failed = true
+			continue
+		}
+		n := len(*errs)
 		errs.Add(result)
+		for _, line := range (*errs)[n:] {
+			if strings.TrimSpace(line) != "" && ParseIssue(line).File == "" {
+				failed = true
+			}
+		}
+	}
+	if failed {
+		return &ToolError{Tool: bin, Err: Error((*errs)...)}
 	}
 	return Error((*errs)...)
 }
 
+// Command returns an exec.Cmd that runs name with args. The command and any
+// processes it starts are killed if ctx is done before the command completes.
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	killProcessGroup(cmd)
+	return cmd
+}
+
 // ExecResult holds a status code, stdout and stderr for a single command execution.
 type ExecResult struct {
 	Code   int
@@ -197,6 +326,8 @@ type Package struct {
 	GoFiles []string
 	// All sub packages if Path is a wildcard, or just Path if not.
 	Pkgs []string
+	// The directory of each package in Pkgs.
+	Dirs []string
 	// build.Package instance for this package
 	Build *build.Package
 }
@@ -243,11 +374,7 @@ func (p *Package) load() error {
 
 func (p *Package) readFiles() error {
 	var res []string
-	for _, pkg := range p.Pkgs {
-		dir, err := packageDir(pkg)
-		if err != nil {
-			return err
-		}
+	for _, dir := range p.Dirs {
 		entries, err := ioutil.ReadDir(dir)
 		if err != nil {
 			return fmt.Errorf("failed to list dir %s: %v", dir, err)
@@ -292,28 +419,39 @@ func SkipDirs(fns ...func(path, name string) bool) func(string, string) bool {
 // if the directory should be skipped.
 var SkipDirFunc = SkipDirs(SkipUnderscoreDirs, SkipTestdata)
 
+// readPackages resolves the packages matching p.Path. In module mode, which is
+// used when the working directory or the directory named by a relative path is
+// part of a module or workspace, packages are resolved using go list. Otherwise
+// the GOPATH is used.
 func (p *Package) readPackages() error {
 	wd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to find cwd: %v", err)
 	}
+	if moduleMode(wd, p.Path) {
+		return p.listPackages(wd)
+	}
 	if filepath.Base(p.Path) != "..." {
 		var b *build.Package
 		if b, err = build.Import(p.Path, wd, build.FindOnly); err != nil {
 			return fmt.Errorf("import failed: %s: %v", p.Path, err)
 		}
-		p.Pkgs, p.Build = []string{b.ImportPath}, b
+		p.Pkgs, p.Dirs, p.Build = []string{b.ImportPath}, []string{b.Dir}, b
 		return nil
 	}
 
 	d := filepath.Dir(p.Path)
+	if build.IsLocalImport(p.Path) && !build.IsLocalImport(d) {
+		// filepath.Dir removes the ./ prefix of paths such as ./pkg/...
+		d = "./" + d
+	}
 	b, err := build.Import(d, wd, build.FindOnly)
 	if err != nil {
 		return fmt.Errorf("import failed %s: %v", d, err)
 	}
 	p.Build = b
 	dir := b.Dir
-	var paths []string
+	var paths, dirs []string
 	err = filepath.Walk(dir, func(path string, stat os.FileInfo, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
@@ -331,12 +469,12 @@ func (p *Package) readPackages() error {
 			}
 			return fmt.Errorf("import failed: %s: %v", path, perr)
 		}
-		paths = append(paths, p.ImportPath)
+		paths, dirs = append(paths, p.ImportPath), append(dirs, path)
 		return nil
 	})
 	if err != nil {
 		return fmt.Errorf("failed to list %s: %v", dir, err)
 	}
-	p.Pkgs = paths
+	p.Pkgs, p.Dirs = paths, dirs
 	return nil
 }
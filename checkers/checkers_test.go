@@ -0,0 +1,202 @@
+package checkers_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+type loadTest struct {
+	Path string
+	Pkgs []string
+	Dirs []string
+}
+
+func testLoad(t *testing.T, tests []loadTest) {
+	for i, test := range tests {
+		checkers.Unload(test.Path)
+		p, err := checkers.Load(test.Path)
+		if err != nil {
+			t.Error("Load", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(p.Pkgs, test.Pkgs) {
+			t.Error("Load", i, "expected", test.Pkgs, "got", p.Pkgs)
+		}
+		if !reflect.DeepEqual(p.Dirs, test.Dirs) {
+			t.Error("Load", i, "expected", test.Dirs, "got", p.Dirs)
+		}
+		checkers.Unload(test.Path)
+	}
+}
+
+func moduleEnv(t *testing.T) string {
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOWORK", "")
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoadModules(t *testing.T) {
+	tmp := moduleEnv(t)
+	writeFiles(t, tmp, map[string]string{
+		"root/go.mod": "module example.com/root\n\ngo 1.16\n\n" +
+			"require example.com/dep v0.0.0\n\nreplace example.com/dep => ../dep\n",
+		"root/a/a.go":            "package a\n",
+		"root/b/b.go":            "package b\n\nimport _ \"example.com/dep/sub\"\n",
+		"root/testdata/t/t.go":   "package t\n",
+		"root/_skipped/s.go":     "package skipped\n",
+		"root/nested/go.mod":     "module example.com/nested\n\ngo 1.16\n",
+		"root/nested/n.go":       "package nested\n",
+		"root/nested/inner/i.go": "package inner\n",
+		"dep/go.mod":             "module example.com/dep\n\ngo 1.16\n",
+		"dep/d.go":               "package dep\n",
+		"dep/sub/s.go":           "package sub\n",
+	})
+	root := filepath.Join(tmp, "root")
+	chdir(t, root)
+
+	testLoad(t, []loadTest{
+		{
+			Path: "./...",
+			Pkgs: []string{"example.com/root/a", "example.com/root/b"},
+			Dirs: []string{filepath.Join(root, "a"), filepath.Join(root, "b")},
+		},
+		{
+			Path: "./a",
+			Pkgs: []string{"example.com/root/a"},
+			Dirs: []string{filepath.Join(root, "a")},
+		},
+		{
+			Path: "example.com/root/b",
+			Pkgs: []string{"example.com/root/b"},
+			Dirs: []string{filepath.Join(root, "b")},
+		},
+		{
+			Path: "./nested/...",
+			Pkgs: []string{"example.com/nested", "example.com/nested/inner"},
+			Dirs: []string{filepath.Join(root, "nested"), filepath.Join(root, "nested", "inner")},
+		},
+		{
+			Path: filepath.Join(root, "nested", "inner"),
+			Pkgs: []string{"example.com/nested/inner"},
+			Dirs: []string{filepath.Join(root, "nested", "inner")},
+		},
+		{
+			Path: "example.com/dep/...",
+			Pkgs: []string{"example.com/dep", "example.com/dep/sub"},
+			Dirs: []string{filepath.Join(tmp, "dep"), filepath.Join(tmp, "dep", "sub")},
+		},
+	})
+
+	files, err := checkers.GoFiles("./a")
+	if err != nil || !reflect.DeepEqual(files, []string{filepath.Join(root, "a", "a.go")}) {
+		t.Error("GoFiles", files, err)
+	}
+	checkers.Unload("./a")
+
+	checkers.SkipDirFunc = checkers.SkipDirs(checkers.SkipDirFunc, func(path, name string) bool { return name == "b" })
+	defer func() { checkers.SkipDirFunc = checkers.SkipDirs(checkers.SkipUnderscoreDirs, checkers.SkipTestdata) }()
+	testLoad(t, []loadTest{
+		{Path: "./...", Pkgs: []string{"example.com/root/a"}, Dirs: []string{filepath.Join(root, "a")}},
+	})
+}
+
+func TestLoadWorkspace(t *testing.T) {
+	tmp := moduleEnv(t)
+	writeFiles(t, tmp, map[string]string{
+		"go.work":      "go 1.18\n\nuse (\n\t./one\n\t./two\n)\n",
+		"one/go.mod":   "module example.com/one\n\ngo 1.18\n",
+		"one/one.go":   "package one\n",
+		"two/go.mod":   "module example.com/two\n\ngo 1.18\n",
+		"two/two.go":   "package two\n",
+		"two/x/x.go":   "package x\n",
+		"other/o.go":   "package other\n",
+		"other/go.mod": "module example.com/other\n\ngo 1.18\n",
+	})
+	t.Setenv("GOFLAGS", "")
+	chdir(t, filepath.Join(tmp, "one"))
+
+	testLoad(t, []loadTest{
+		{
+			Path: "example.com/two/...",
+			Pkgs: []string{"example.com/two", "example.com/two/x"},
+			Dirs: []string{filepath.Join(tmp, "two"), filepath.Join(tmp, "two", "x")},
+		},
+		{
+			Path: "../two/x",
+			Pkgs: []string{"example.com/two/x"},
+			Dirs: []string{filepath.Join(tmp, "two", "x")},
+		},
+	})
+
+	if _, err := checkers.Load("example.com/other"); err == nil {
+		t.Error("expected an error loading a package outside the workspace")
+	}
+}
+
+func TestLintFailures(t *testing.T) {
+	dir := moduleEnv(t)
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.16\n",
+		"a/a.go": "package a\n",
+	})
+	chdir(t, dir)
+	ctx := context.Background()
+	for i, test := range []struct {
+		script string
+		failed bool
+		errs   []string
+	}{
+		{"echo 'a.go:1: issue'", false, []string{"a.go:1: issue"}},
+		{"echo 'a.go:1: issue'; echo 'internal error' >&2; exit 1", true, []string{"a.go:1: issue", "internal error"}},
+		{"true", false, nil},
+	} {
+		err := checkers.LintContext(ctx, "sh", "", "sh", []string{"./a"}, "-c", test.script)
+		_, failed := err.(*checkers.ToolError)
+		var errs []string
+		if e, ok := err.(interface{ Errors() []string }); ok {
+			errs = e.Errors()
+		}
+		if failed != test.failed || !reflect.DeepEqual(errs, test.errs) {
+			t.Errorf("%d: unexpected error %T %v", i, err, err)
+		}
+	}
+	err := checkers.LintContext(ctx, "sh", "", "sh", []string{"./missing"}, "-c", "true")
+	if _, ok := err.(*checkers.ToolError); !ok {
+		t.Errorf("expected a tool error for a missing package, got %T %v", err, err)
+	}
+}
@@ -0,0 +1,95 @@
+package checkers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HashPackages returns a hash for each package matching pkgs, which are
+// resolved using Load, keyed by the directory of the package. The hash covers
+// the files in the directory of the package and of every package it imports
+// outside the standard library, so that it changes whenever any of these files
+// change. Imports are found using a single go list call for each of pkgs, in
+// module mode or GOPATH mode as described in Load.
+func HashPackages(pkgs ...string) (map[string]string, error) {
+	hashes := map[string]string{}
+	dirs := map[string][]byte{}
+	for _, pkg := range pkgs {
+		p, err := Load(pkg)
+		if err != nil {
+			return nil, err
+		}
+		listed, err := p.listDeps()
+		if err != nil {
+			return nil, err
+		}
+		byPath := map[string]*listedPackage{}
+		for i := range listed {
+			byPath[listed[i].ImportPath] = &listed[i]
+		}
+		for _, l := range listed {
+			if l.DepOnly || hashes[l.Dir] != "" {
+				continue
+			}
+			h := sha256.New()
+			for _, path := range append([]string{l.ImportPath}, l.Deps...) {
+				dep := byPath[path]
+				if dep == nil {
+					return nil, fmt.Errorf("failed to list imports of %s: %s: not found", l.Dir, path)
+				}
+				// Dirs returned by Load in GOPATH mode include directories
+				// without Go files, which are hashed like any other.
+				if dep.Error != nil && !(path == l.ImportPath && strings.HasPrefix(dep.Error.Err, "no Go files")) {
+					return nil, fmt.Errorf("failed to list imports of %s: %s: %s", l.Dir, path, dep.Error.Err)
+				}
+				if dep.Standard {
+					continue
+				}
+				sum, err := hashDir(dep.Dir, dirs)
+				if err != nil {
+					return nil, err
+				}
+				_, _ = fmt.Fprintf(h, "package %s %x\n", path, sum)
+			}
+			hashes[l.Dir] = hex.EncodeToString(h.Sum(nil))
+		}
+	}
+	return hashes, nil
+}
+
+// hashDir returns a hash of the name and contents of each file in dir,
+// excluding sub directories. Hashes are stored in sums, so that each directory
+// is read once.
+func hashDir(dir string, sums map[string][]byte) ([]byte, error) {
+	if sum, ok := sums[dir]; ok {
+		return sum, nil
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dir %s: %v", dir, err)
+	}
+	h := sha256.New()
+	for _, entry := range entries {
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %v", entry.Name(), err)
+		}
+		_, _ = fmt.Fprintf(h, "file %s %d\n", entry.Name(), entry.Size())
+		_, err = io.Copy(h, f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %v", entry.Name(), err)
+		}
+	}
+	sums[dir] = h.Sum(nil)
+	return sums[dir], nil
+}
@@ -0,0 +1,111 @@
+package checkers
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ImportGraph holds a set of packages along with every package they import,
+// directly or indirectly.
+type ImportGraph struct {
+	// Roots holds the import paths of the packages the graph was loaded for.
+	Roots []string
+	// Packages maps import paths to packages.
+	Packages map[string]*ImportedPackage
+}
+
+// ImportedPackage is a package in an ImportGraph.
+type ImportedPackage struct {
+	ImportPath string
+	Dir        string
+	// GoFiles holds the paths of the Go files of the package, excluding tests
+	// and files that import "C".
+	GoFiles []string
+	// CgoFiles holds the paths of the Go files of the package that import "C".
+	CgoFiles []string
+	// Imports holds the import paths of the packages imported by GoFiles and
+	// CgoFiles.
+	Imports []string
+	// ImportMap maps import paths used in the files to the paths in Imports
+	// when they differ, such as for vendored packages.
+	ImportMap map[string]string
+	// Standard is true for packages in the standard library.
+	Standard bool
+}
+
+// LoadImports returns the import graph of the packages matching pkgs, which
+// are resolved using Load. Imports are found using a single go list call for
+// each of pkgs, in module mode or GOPATH mode as described in Load.
+func LoadImports(pkgs ...string) (*ImportGraph, error) {
+	g := &ImportGraph{Packages: map[string]*ImportedPackage{}}
+	roots := map[string]bool{}
+	for _, pkg := range pkgs {
+		p, err := Load(pkg)
+		if err != nil {
+			return nil, err
+		}
+		listed, err := p.listDeps()
+		if err != nil {
+			return nil, err
+		}
+		// Roots are listed in dependency order, so they are added in the
+		// order of p.Dirs instead.
+		rootDirs := map[string]string{}
+		for _, l := range listed {
+			if l.Error != nil && !l.DepOnly && strings.HasPrefix(l.Error.Err, "no Go files") {
+				// Dirs returned by Load in GOPATH mode include directories
+				// without Go files.
+				continue
+			}
+			if l.Error != nil && !l.DepOnly {
+				return nil, fmt.Errorf("failed to list imports of %s: %s: %s", l.Dir, l.ImportPath, l.Error.Err)
+			}
+			if !l.DepOnly {
+				rootDirs[l.Dir] = l.ImportPath
+			}
+			if g.Packages[l.ImportPath] != nil {
+				continue
+			}
+			g.Packages[l.ImportPath] = &ImportedPackage{
+				ImportPath: l.ImportPath,
+				Dir:        l.Dir,
+				GoFiles:    join(l.Dir, l.GoFiles),
+				CgoFiles:   join(l.Dir, l.CgoFiles),
+				Imports:    l.Imports,
+				ImportMap:  l.ImportMap,
+				Standard:   l.Standard,
+			}
+		}
+		for _, dir := range p.Dirs {
+			if root, ok := rootDirs[dir]; ok && !roots[root] {
+				roots[root] = true
+				g.Roots = append(g.Roots, root)
+			}
+		}
+	}
+	return g, nil
+}
+
+// join returns the paths of files in dir.
+func join(dir string, files []string) []string {
+	res := make([]string, len(files))
+	for i, f := range files {
+		res[i] = filepath.Join(dir, f)
+	}
+	return res
+}
+
+// MatchPackage returns true if the import path matches pattern. As with go
+// list, ... in pattern matches any string, and a pattern ending in /... also
+// matches the path before it, so that net/... matches net and net/http.
+func MatchPackage(pattern, path string) bool {
+	re := regexp.QuoteMeta(pattern)
+	re = strings.Replace(re, `\.\.\.`, `.*`, -1)
+	if strings.HasSuffix(re, `/.*`) {
+		re = strings.TrimSuffix(re, `/.*`) + `(/.*)?`
+	}
+	matched, _ := regexp.MatchString("^"+re+"$", path)
+	return matched
+}
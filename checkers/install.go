@@ -0,0 +1,234 @@
+package checkers
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Binary describes a linter that is installed from a pinned module version, so
+// that every machine reports the same results for the same code.
+//
+// Binaries are installed using
+//
+//    go install Package@Version
+//
+// into a directory of BinDir named after the binary and version. An existing
+// binary is only used if its build information shows that it was built from
+// Module at Version. It is reinstalled otherwise.
+type Binary struct {
+	// Name is the name of the binary.
+	Name string
+	// Package is the import path of the main package to install.
+	Package string
+	// Module is the path of the module containing Package.
+	Module string
+	// Version is the version of Module to install.
+	Version string
+}
+
+// String returns the package and version of b, as passed to go install.
+func (b Binary) String() string { return b.Package + "@" + b.Version }
+
+// BinDir returns the directory holding installed binaries. This is the
+// directory named by the LINT_BIN environment variable, or a lint directory in
+// the user cache directory if it is not set.
+func BinDir() (string, error) {
+	if dir := os.Getenv("LINT_BIN"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find bin directory: %v", err)
+	}
+	return filepath.Join(dir, "surullabs-lint", "bin"), nil
+}
+
+// Offline disables downloads when installing linters. It defaults to true if
+// the LINT_OFFLINE environment variable is set to 1.
+//
+// In offline mode a Binary that is not installed is only installed from a local
+// module proxy, if GOPROXY only lists file:// URLs, or from the vendor
+// directory of the module in the current directory, if GOFLAGS includes
+// -mod=vendor. This requires the module to depend on the linter, usually using
+// a tools.go file. Otherwise an error listing the commands needed to install
+// the linter is returned.
+var Offline = os.Getenv("LINT_OFFLINE") == "1"
+
+// Path returns the path b is installed at in BinDir.
+func (b Binary) Path() (string, error) {
+	dir, err := BinDir()
+	if err != nil {
+		return "", err
+	}
+	return b.pathIn(dir), nil
+}
+
+func (b Binary) pathIn(dir string) string {
+	name := b.Name
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(dir, b.Name+"@"+b.Version, name)
+}
+
+// Verify returns an error if the binary at path was not built from Module at
+// Version. Binaries built from a vendor directory, where Module is a
+// dependency of the main module, are also accepted.
+func (b Binary) Verify(path string) error {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read build info of %s: %v", path, err)
+	}
+	mod := &info.Main
+	if mod.Path != b.Module {
+		for _, dep := range info.Deps {
+			if dep.Path == b.Module {
+				mod = dep
+				break
+			}
+		}
+	}
+	if mod.Path != b.Module || mod.Version != b.Version {
+		return fmt.Errorf("%s was built from %s@%s, expected %s@%s",
+			path, mod.Path, mod.Version, b.Module, b.Version)
+	}
+	return nil
+}
+
+// Install installs b in BinDir if it is missing or does not match Version,
+// and returns its path. It returns an *InstallError on failure.
+func (b Binary) Install() (string, error) {
+	dir, err := BinDir()
+	if err != nil {
+		return "", &InstallError{b.Name, err}
+	}
+	return b.InstallIn(dir)
+}
+
+// InstallIn is identical to Install, except that b is installed in dir
+// instead of BinDir.
+//
+// Concurrent installs of b, such as those of test binaries run in parallel by
+// go test ./..., are serialized using a lock file, so that b is installed only
+// once and other processes reuse it. b is built in a temporary directory and
+// renamed into place, so that a partially written binary is never run.
+func (b Binary) InstallIn(dir string) (string, error) {
+	path := b.pathIn(dir)
+	if b.installed(path) {
+		return path, nil
+	}
+	gobin := filepath.Dir(path)
+	if err := os.MkdirAll(gobin, 0755); err != nil {
+		return "", &InstallError{b.Name, fmt.Errorf("failed to create %s: %v", gobin, err)}
+	}
+	unlock, err := lockFile(filepath.Join(gobin, "install.lock"))
+	if err != nil {
+		return "", &InstallError{b.Name, err}
+	}
+	defer unlock()
+	// Another process may have installed b while this one waited for the lock.
+	if b.installed(path) {
+		return path, nil
+	}
+	tmp, err := ioutil.TempDir(gobin, "tmp-")
+	if err != nil {
+		return "", &InstallError{b.Name, fmt.Errorf("failed to create temporary directory: %v", err)}
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+	cmd, err := b.installCommand(gobin, tmp)
+	if err != nil {
+		return "", &InstallError{b.Name, err}
+	}
+	data, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", &InstallError{b.Name, fmt.Errorf("failed to install %s: %v: %s", b, err, string(data))}
+	}
+	built := filepath.Join(tmp, filepath.Base(path))
+	if err = b.Verify(built); err != nil {
+		return "", &InstallError{b.Name, fmt.Errorf("failed to verify %s after install: %v", b, err)}
+	}
+	if err = os.Rename(built, path); err != nil {
+		return "", &InstallError{b.Name, fmt.Errorf("failed to install %s: %v", b, err)}
+	}
+	return path, nil
+}
+
+// installed returns true if path holds b.
+func (b Binary) installed(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil && b.Verify(path) == nil
+}
+
+// installCommand returns the command used to install b in tmp, before it is
+// moved to gobin.
+func (b Binary) installCommand(gobin, tmp string) (*exec.Cmd, error) {
+	// Install in module mode irrespective of the settings of the code being
+	// checked. Later entries take precedence.
+	env := append(os.Environ(), "GOBIN="+tmp, "GO111MODULE=on", "GOFLAGS=", "GOWORK=off")
+	if !Offline {
+		cmd := exec.Command("go", "install", b.String())
+		cmd.Env, cmd.Dir = env, tmp
+		return cmd, nil
+	}
+	proxy, flags := goEnv("GOPROXY"), goEnv("GOFLAGS")
+	switch {
+	case localProxy(proxy):
+		cmd := exec.Command("go", "install", b.String())
+		cmd.Env, cmd.Dir = append(env, "GOPROXY="+proxy), tmp
+		if goEnv("GOSUMDB") == "sum.golang.org" {
+			// The checksum database cannot be reached.
+			cmd.Env = append(cmd.Env, "GOSUMDB=off")
+		}
+		return cmd, nil
+	case hasFlag(flags, "-mod=vendor"):
+		cmd := exec.Command("go", "install", b.Package)
+		cmd.Env = append(os.Environ(), "GOBIN="+tmp, "GOFLAGS="+flags)
+		return cmd, nil
+	default:
+		return nil, fmt.Errorf(`%s is not installed and downloads are disabled in offline mode. Either install it using
+	GOBIN=%s go install %s
+on a machine with network access and copy %s to this machine, or make it available using
+	GOPROXY=file:///path/to/module/proxy
+	GOFLAGS=-mod=vendor (with %s required by the current module)`,
+			b.Name, gobin, b, gobin, b.Module)
+	}
+}
+
+// goEnv returns the value of the go environment variable name, which includes
+// values set using go env -w.
+func goEnv(name string) string {
+	out, err := exec.Command("go", "env", name).Output()
+	if err != nil {
+		return os.Getenv(name)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// localProxy returns true if proxy only lists file:// URLs.
+func localProxy(proxy string) bool {
+	local := false
+	for _, p := range strings.FieldsFunc(proxy, func(r rune) bool { return r == ',' || r == '|' }) {
+		switch {
+		case strings.HasPrefix(p, "file://"):
+			local = true
+		case p != "off":
+			return false
+		}
+	}
+	return local
+}
+
+func hasFlag(flags, flag string) bool {
+	for _, f := range strings.Fields(flags) {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package checkers_test
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+// writeProxy writes version of module, holding a main package in its root, to
+// a module proxy directory that can be used with GOPROXY=file://dir.
+func writeProxy(t *testing.T, dir, module, version, src string) {
+	vdir := filepath.Join(dir, filepath.FromSlash(module), "@v")
+	gomod := "module " + module + "\n\ngo 1.16\n"
+	writeFiles(t, vdir, map[string]string{
+		version + ".info": `{"Version":"` + version + `"}`,
+		version + ".mod":  gomod,
+	})
+	f, err := os.OpenFile(filepath.Join(vdir, "list"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.WriteString(version + "\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = os.Create(filepath.Join(vdir, version+".zip")); err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, content := range map[string]string{"go.mod": gomod, "main.go": src} {
+		zf, cerr := w.Create(module + "@" + version + "/" + name)
+		if cerr != nil {
+			t.Fatal(cerr)
+		}
+		if _, err = zf.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// proxyEnv sets up a module proxy holding two versions of example.com/tool and
+// an empty bin directory.
+func proxyEnv(t *testing.T) (proxy, bin string) {
+	proxy, bin = t.TempDir(), t.TempDir()
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		writeProxy(t, proxy, "example.com/tool", v, "package main\n\nfunc main() { println(\""+v+"\") }\n")
+	}
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(proxy))
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOTOOLCHAIN", "local")
+	t.Setenv("LINT_BIN", bin)
+	return proxy, bin
+}
+
+func TestBinaryInstall(t *testing.T) {
+	_, bin := proxyEnv(t)
+	b := checkers.Binary{Name: "tool", Package: "example.com/tool", Module: "example.com/tool", Version: "v1.0.0"}
+	path, err := b.Install()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(bin, "tool@v1.0.0", "tool") {
+		t.Fatal("unexpected path", path)
+	}
+	if err = b.Verify(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// A binary built from a different version is replaced.
+	other := b
+	other.Version = "v1.1.0"
+	otherPath, err := other.Install()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(otherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(path, data, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Verify(path); err == nil || !strings.Contains(err.Error(), "was built from example.com/tool@v1.1.0, expected example.com/tool@v1.0.0") {
+		t.Fatal("unexpected error", err)
+	}
+	if _, err = b.Install(); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Verify(path); err != nil {
+		t.Fatal("binary not reinstalled", err)
+	}
+
+	missing := b
+	missing.Version = "v2.0.0"
+	_, err = missing.Install()
+	if ierr, ok := err.(*checkers.InstallError); !ok || ierr.Bin != "tool" ||
+		!strings.Contains(err.Error(), "failed to install example.com/tool@v2.0.0") {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func TestOfflineInstall(t *testing.T) {
+	_, bin := proxyEnv(t)
+	defer func(offline bool) { checkers.Offline = offline }(checkers.Offline)
+	checkers.Offline = true
+
+	// Installing from a local module proxy is allowed.
+	b := checkers.Binary{Name: "tool", Package: "example.com/tool", Module: "example.com/tool", Version: "v1.0.0"}
+	if _, err := b.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An installed binary is used without downloading anything.
+	t.Setenv("GOPROXY", "https://proxy.golang.org")
+	t.Setenv("GOFLAGS", "")
+	if _, err := b.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	missing := b
+	missing.Version = "v1.1.0"
+	_, err := missing.Install()
+	if ierr, ok := err.(*checkers.InstallError); !ok || ierr.Bin != "tool" {
+		t.Fatal("unexpected error", err)
+	}
+	for _, want := range []string{
+		"downloads are disabled in offline mode",
+		"GOBIN=" + filepath.Join(bin, "tool@v1.1.0") + " go install example.com/tool@v1.1.0",
+		"GOPROXY=file://",
+		"GOFLAGS=-mod=vendor",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not contain %q: %v", want, err)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(bin, "tool@v1.1.0", "tool")); !os.IsNotExist(err) {
+		t.Fatal("binary installed in offline mode", err)
+	}
+}
+
+func TestConcurrentInstall(t *testing.T) {
+	_, bin := proxyEnv(t)
+	b := checkers.Binary{Name: "tool", Package: "example.com/tool", Module: "example.com/tool", Version: "v1.0.0"}
+	errs := make(chan error)
+	for i := 0; i < 4; i++ {
+		go func() {
+			path, err := b.Install()
+			if err == nil {
+				err = b.Verify(path)
+			}
+			errs <- err
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	// Only the binary and the lock remain.
+	files, err := ioutil.ReadDir(filepath.Join(bin, "tool@v1.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	if strings.Join(names, " ") != "install.lock tool" {
+		t.Fatal("unexpected files", names)
+	}
+}
@@ -0,0 +1,130 @@
+package checkers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity is the severity of an Issue.
+type Severity string
+
+// Severities assigned to issues.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Issue holds a single finding reported by a checker.
+type Issue struct {
+	// Checker is the name of the checker that reported the issue. It is set by
+	// lint.Group and is empty for issues returned directly by a checker.
+	Checker string
+	// File is the path of the file containing the issue, if known.
+	File string
+	// Line is the 1 based line number of the issue or 0 if unknown.
+	Line int
+	// Column is the 1 based column of the issue or 0 if unknown.
+	Column int
+	// Severity of the issue.
+	Severity Severity
+	// RuleID identifies the rule that was violated, if the linter reports one.
+	RuleID string
+	// Message is the description of the issue without location information.
+	Message string
+	// Raw is the text of the issue as reported by the linter.
+	Raw string
+}
+
+// String returns Raw prefixed with the Checker if one is set. This is the
+// same string returned for the issue by the Errors method of the error
+// returned by Issues.
+func (i Issue) String() string {
+	if i.Checker == "" {
+		return i.Raw
+	}
+	return i.Checker + ": " + i.Raw
+}
+
+var (
+	// The file may start with a Windows drive letter but otherwise contains no
+	// colons, so that text before the file is not taken as part of it.
+	locationRE = regexp.MustCompile(`(?s)^((?:[A-Za-z]:[\\/])?[^\s:][^:]*?\.go):([0-9]+)(?::([0-9]+))?:\s*(.*)$`)
+	ruleRE     = regexp.MustCompile(`\s*\(([A-Z]+[0-9]+)\)$`)
+)
+
+// ParseIssue creates an Issue from a line of linter output. Output of the form
+//
+//     file.go:line:column: message
+//     file.go:line: message
+//
+// is split into its components. A trailing rule identifier such as (SA1000),
+// as reported by staticcheck, is stored in RuleID. Any other output is
+// stored as the Message with no location information.
+//
+// Located output may be prefixed by the name of a checker, such as
+// govet.Check, as returned by the String method of Issue. The name is stored
+// in Checker and removed from Raw.
+func ParseIssue(raw string) Issue {
+	if i := strings.Index(raw, ": "); i > 0 && strings.Contains(raw[:i], ".") && !strings.ContainsAny(raw[:i], " :/\\") {
+		if issue := parseIssue(raw[i+2:]); issue.File != "" {
+			issue.Checker = raw[:i]
+			return issue
+		}
+	}
+	return parseIssue(raw)
+}
+
+func parseIssue(raw string) Issue {
+	issue := Issue{Severity: SeverityError, Message: raw, Raw: raw}
+	m := locationRE.FindStringSubmatch(raw)
+	if m == nil {
+		return issue
+	}
+	issue.File, issue.Message = m[1], strings.TrimSpace(m[4])
+	issue.Line, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		issue.Column, _ = strconv.Atoi(m[3])
+	}
+	if r := ruleRE.FindStringSubmatchIndex(issue.Message); r != nil {
+		issue.RuleID = issue.Message[r[2]:r[3]]
+		issue.Message = issue.Message[:r[0]]
+	}
+	return issue
+}
+
+// Issues returns an error containing issues.
+//
+// If issues is empty, nil is returned. If not the returned
+// error will implement the following interfaces
+//
+//     type errors interface {
+//     	Errors() []string
+//     }
+//
+//     type issues interface {
+//     	Issues() []Issue
+//     }
+//
+// Errors returns the result of calling String on each issue.
+func Issues(issues ...Issue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	return issueList(issues)
+}
+
+type issueList []Issue
+
+func (l issueList) Issues() []Issue { return []Issue(l) }
+
+func (l issueList) Errors() []string {
+	errs := make([]string, len(l))
+	for i, issue := range l {
+		errs[i] = issue.String()
+	}
+	return errs
+}
+
+func (l issueList) Error() string { return strings.Join(l.Errors(), "\n") }
@@ -0,0 +1,52 @@
+package checkers_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+func TestParseIssue(t *testing.T) {
+	for _, test := range []struct {
+		raw      string
+		expected checkers.Issue
+	}{
+		{
+			"file.go:23: err is shadowed",
+			checkers.Issue{File: "file.go", Line: 23, Message: "err is shadowed"},
+		},
+		{
+			"pkg/file.go:6:1: bad call (SA1000)",
+			checkers.Issue{File: "pkg/file.go", Line: 6, Column: 1, RuleID: "SA1000", Message: "bad call"},
+		},
+		{
+			`C:\src\file.go:6:1: bad call`,
+			checkers.Issue{File: `C:\src\file.go`, Line: 6, Column: 1, Message: "bad call"},
+		},
+		{
+			"govet.Check: file.go:23: err is shadowed",
+			checkers.Issue{Checker: "govet.Check", File: "file.go", Line: 23, Message: "err is shadowed"},
+		},
+		{
+			"error: failed to check packages: /x/file.go:6:1: expected declaration",
+			checkers.Issue{Message: "error: failed to check packages: /x/file.go:6:1: expected declaration"},
+		},
+		{
+			"govet.Check: no issues in file.go",
+			checkers.Issue{Message: "govet.Check: no issues in file.go"},
+		},
+	} {
+		issue := checkers.ParseIssue(test.raw)
+		test.expected.Severity = checkers.SeverityError
+		if test.expected.Raw = test.raw; test.expected.Checker != "" {
+			test.expected.Raw = test.raw[len(test.expected.Checker)+2:]
+		}
+		if !reflect.DeepEqual(issue, test.expected) {
+			t.Errorf("%q: expected %#v, got %#v", test.raw, test.expected, issue)
+		}
+		if issue.String() != test.raw {
+			t.Errorf("%q: String returned %q", test.raw, issue.String())
+		}
+	}
+}
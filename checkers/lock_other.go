@@ -0,0 +1,35 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package checkers
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// staleLock is the age after which a lock is assumed to have been left behind
+// by a process that exited without releasing it.
+const staleLock = 10 * time.Minute
+
+// lockFile acquires an exclusive lock on path by creating it, and waits until
+// any other process holding the lock removes it. The returned function
+// releases the lock.
+func lockFile(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock %s: %v", path, err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			_ = os.Remove(path)
+			continue
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,176 @@
+package checkers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// listedPackage holds the fields of go list -json output used by Load,
+// HashPackages and LoadImports.
+type listedPackage struct {
+	Dir        string
+	ImportPath string
+	Name       string
+	Standard   bool
+	DepOnly    bool
+	GoFiles    []string
+	CgoFiles   []string
+	Imports    []string
+	ImportMap  map[string]string
+	Deps       []string
+	Error      *struct {
+		Err string
+	}
+}
+
+// goList runs go list -e -json for args in dir and returns the listed
+// packages. args holds patterns, optionally preceded by flags such as -deps.
+func goList(dir string, args ...string) ([]listedPackage, error) {
+	cmd := exec.Command("go", append([]string{"list", "-e", "-json"}, args...)...)
+	cmd.Dir = dir
+	res, err := Exec(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("go list %s failed: %v: %s", strings.Join(args, " "), err, res.Stderr)
+	}
+	var pkgs []listedPackage
+	dec := json.NewDecoder(strings.NewReader(res.Stdout))
+	for {
+		var pkg listedPackage
+		if err = dec.Decode(&pkg); err == io.EOF {
+			return pkgs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse go list output: %v", err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+}
+
+// listDeps lists the packages in p.Dirs along with every package they import,
+// directly or indirectly, using a single go list call.
+func (p *Package) listDeps() ([]listedPackage, error) {
+	if len(p.Dirs) == 0 {
+		return nil, nil
+	}
+	return goList(p.Build.Dir, append([]string{"-deps"}, p.Dirs...)...)
+}
+
+// moduleMode returns true if packages matching path must be resolved in module
+// mode. This is the case when go env reports a go.mod or go.work file for the
+// directory path is resolved from.
+func moduleMode(wd, path string) bool {
+	cmd := exec.Command("go", "env", "GOMOD", "GOWORK")
+	cmd.Dir = wd
+	if dir := localDir(wd, path); dir != "" {
+		cmd.Dir = dir
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	for _, f := range strings.Split(string(bytes.TrimSpace(out)), "\n") {
+		if f = strings.TrimSpace(f); f != "" && f != os.DevNull && f != "off" {
+			return true
+		}
+	}
+	return false
+}
+
+// localDir returns the absolute directory named by path, excluding any
+// wildcard suffix, if path is a relative or absolute file system path. It
+// returns an empty string for import paths.
+func localDir(wd, path string) string {
+	if !filepath.IsAbs(path) && !build.IsLocalImport(path) {
+		return ""
+	}
+	if filepath.Base(path) == "..." {
+		path = filepath.Dir(path)
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(wd, path)
+}
+
+// listPackages resolves p.Path using go list. Relative and absolute paths are
+// listed from their own directory, so that they resolve within the module
+// containing them, including nested modules. Wildcards only match packages in
+// the same module as the directory they are rooted at. Directories skipped by
+// SkipDirFunc are excluded from wildcard matches.
+func (p *Package) listPackages(wd string) error {
+	wildcard := filepath.Base(p.Path) == "..."
+	root := p.Path
+	if wildcard {
+		root = filepath.Dir(p.Path)
+	}
+	dir, pattern := wd, root
+	if local := localDir(wd, p.Path); local != "" {
+		dir, pattern = local, "."
+	}
+	roots, err := goList(dir, pattern)
+	if err != nil {
+		return err
+	}
+	if len(roots) != 1 || roots[0].Dir == "" {
+		msg := "not found"
+		if len(roots) > 0 && roots[0].Error != nil {
+			msg = roots[0].Error.Err
+		}
+		return fmt.Errorf("import failed: %s: %s", root, msg)
+	}
+	r := roots[0]
+	p.Build = &build.Package{Dir: r.Dir, ImportPath: r.ImportPath, Name: r.Name}
+	if !wildcard {
+		p.Pkgs, p.Dirs = []string{r.ImportPath}, []string{r.Dir}
+		return nil
+	}
+
+	if dir != wd {
+		dir, pattern = r.Dir, "./..."
+	} else {
+		pattern = p.Path
+	}
+	pkgs, err := goList(dir, pattern)
+	if err != nil {
+		return err
+	}
+	var paths, dirs []string
+	for _, pkg := range pkgs {
+		if pkg.Dir == "" {
+			msg := "not found"
+			if pkg.Error != nil {
+				msg = pkg.Error.Err
+			}
+			return fmt.Errorf("import failed: %s: %s", pkg.ImportPath, msg)
+		}
+		if skipDir(r.Dir, pkg.Dir) {
+			continue
+		}
+		paths, dirs = append(paths, pkg.ImportPath), append(dirs, pkg.Dir)
+	}
+	p.Pkgs, p.Dirs = paths, dirs
+	return nil
+}
+
+// skipDir returns true if SkipDirFunc skips dir or any of its parents up to,
+// but excluding, root.
+func skipDir(root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	path := root
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		path = filepath.Join(path, name)
+		if SkipDirFunc(path, name) {
+			return true
+		}
+	}
+	return false
+}
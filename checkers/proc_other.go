@@ -0,0 +1,9 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package checkers
+
+import "os/exec"
+
+// killProcessGroup is a no-op. Only cmd is killed when its context is done.
+func killProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,41 @@
+package checkers
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	registered    = map[string]interface{}{}
+	registryMutex sync.Mutex
+)
+
+// RegisterChecker registers checker under name so that it can be created by
+// name, for instance from a lint configuration file. checker holds the default
+// options of the checker, which are copied and then overridden by any
+// configured options. Checker packages call this from an init function.
+func RegisterChecker(name string, checker interface{}) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	registered[name] = checker
+}
+
+// LookupChecker returns the checker registered as name.
+func LookupChecker(name string) (interface{}, bool) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	c, ok := registered[name]
+	return c, ok
+}
+
+// RegisteredCheckers returns the names of all registered checkers in sorted order.
+func RegisteredCheckers() []string {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	var names []string
+	for name := range registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
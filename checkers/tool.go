@@ -0,0 +1,72 @@
+package checkers
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Rule describes a kind of issue that a linter reports.
+type Rule struct {
+	// ID is the identifier reported in Issue.RuleID.
+	ID string
+	// Description is a short description of the rule.
+	Description string
+	// HelpURI links to documentation for the rule.
+	HelpURI string
+}
+
+// Tool describes the linter run by a checker.
+type Tool struct {
+	// Name is the name of the linter.
+	Name string
+	// InformationURI links to documentation for the linter.
+	InformationURI string
+	// RuleURI is used to create a help URI for rules that are not part of
+	// Rules. Any %s verb is replaced with the rule ID.
+	RuleURI string
+	// Rules lists the rules the linter is known to report. Issues without a
+	// RuleID use the rule with the same ID as Name.
+	Rules []Rule
+}
+
+// Rule returns the rule with id. If id is not part of Rules, a Rule with a
+// HelpURI created using RuleURI, or InformationURI if RuleURI is empty, is returned.
+func (t Tool) Rule(id string) Rule {
+	for _, r := range t.Rules {
+		if r.ID == id {
+			return r
+		}
+	}
+	r := Rule{ID: id, HelpURI: t.InformationURI}
+	if t.RuleURI != "" {
+		r.HelpURI = fmt.Sprintf(t.RuleURI, id)
+	}
+	return r
+}
+
+var (
+	tools     = map[string]Tool{}
+	toolMutex sync.Mutex
+)
+
+// RegisterTool registers t as the linter run by checkers with the same type as
+// checker. Checker packages call this from an init function.
+func RegisterTool(checker interface{}, t Tool) {
+	toolMutex.Lock()
+	defer toolMutex.Unlock()
+	tools[reflect.TypeOf(checker).String()] = t
+}
+
+// LookupTool returns the Tool registered for the checker with the type name
+// checker, as reported in Issue.Checker. If no tool was registered, a Tool
+// named checker is returned along with false.
+func LookupTool(checker string) (Tool, bool) {
+	toolMutex.Lock()
+	defer toolMutex.Unlock()
+	t, ok := tools[checker]
+	if !ok {
+		t = Tool{Name: checker}
+	}
+	return t, ok
+}
@@ -0,0 +1,30 @@
+package checkers
+
+import "runtime/debug"
+
+// ModuleVersion returns path@version for the module with path that is part of
+// the running binary, as recorded by the go command. If the module is
+// replaced, its replacement is returned instead. An empty string is returned if
+// the module is not part of the binary or it was not built in module mode.
+//
+// In-process checkers use it as part of their version, so that cached results
+// are discarded when the packages they depend on are upgraded.
+func ModuleVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if info.Main.Path == path {
+		return path + "@" + info.Main.Version
+	}
+	for _, m := range info.Deps {
+		if m.Path != path {
+			continue
+		}
+		if m.Replace != nil {
+			m = m.Replace
+		}
+		return m.Path + "@" + m.Version
+	}
+	return ""
+}
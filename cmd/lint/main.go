@@ -0,0 +1,206 @@
+// Command lint runs linters on Go packages, in the same way as lint.Default.Check,
+// without having to write a test.
+//
+//    lint [flags] [packages]
+//
+// Packages default to ./... and are specified as for go test. The default
+// checkers are gofmt, govet, golint, gosimple, gostaticcheck and errcheck. Use
+// -enable and -disable with comma separated checker names to change them.
+// Linters are installed if necessary.
+//
+// With -fix, checkers that can fix issues, such as gofmt, rewrite files before
+// the packages are checked, so that only issues which remain are reported.
+// With -patches, such checkers write patches to a directory instead.
+//
+// With -cache, issues for packages that did not change since they were last
+// checked are read from a cache. See lint.Cached for details.
+//
+// With -prefetch, the linters used by the checkers are installed in a
+// directory and nothing is checked. Copy the directory to machines without
+// network access and set LINT_BIN to it and LINT_OFFLINE to 1 there. See
+// lint.Prefetch for details.
+//
+// Checkers are run in parallel, and issues suppressed by //lint:ignore
+// directives are skipped as described in lint.SkipDirectives.
+//
+// Issues are written to stdout using the format named by -format. The exit
+// status is 0 if no issues were found, 1 if issues were found and 2 if a
+// linter could not be installed or failed to check the packages, for example
+// because it crashed or was misconfigured. Failures are written to stderr.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+	"github.com/surullabs/lint/report"
+
+	// Register dupl, which is not registered by importing lint.
+	_ "github.com/surullabs/lint/dupl"
+)
+
+// Exit codes.
+const (
+	exitOK      = 0
+	exitIssues  = 1
+	exitFailure = 2
+)
+
+// defaultNames holds the names of the checkers in lint.Default.
+const defaultNames = "gofmt,govet,golint,gosimple,gostaticcheck,errcheck"
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	// Nothing useful can be done if stderr cannot be written to.
+	printf := func(format string, args ...interface{}) { _, _ = fmt.Fprintf(stderr, format, args...) }
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	enable := flags.String("enable", "", "comma separated `checkers` to run in addition to the defaults")
+	disable := flags.String("disable", "", "comma separated `checkers` not to run")
+	format := flags.String("format", "text", "output `format`, one of "+strings.Join(report.Names(), ", "))
+	fix := flags.Bool("fix", false, "rewrite files to fix issues before checking them")
+	patches := flags.String("patches", "", "write patches that fix issues to `dir`")
+	cache := flags.Bool("cache", false, "reuse issues for unchanged packages from the cache in $LINT_CACHE or the user cache directory")
+	prefetch := flags.String("prefetch", "", "install the linters used by the checkers in `dir` and exit")
+	flags.Usage = func() {
+		printf("usage: lint [flags] [packages]\n\nflags:\n")
+		flags.PrintDefaults()
+		printf("\ncheckers: %s\ndefault: %s\n", strings.Join(checkers.RegisteredCheckers(), ", "), defaultNames)
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitFailure
+	}
+	write, ok := report.Formats[*format]
+	if !ok {
+		printf("lint: unknown format %q, must be one of %s\n", *format, strings.Join(report.Names(), ", "))
+		return exitFailure
+	}
+	g, err := group(*enable, *disable)
+	if err != nil {
+		printf("lint: %v\n", err)
+		return exitFailure
+	}
+	if *prefetch != "" {
+		if err = lint.Prefetch(*prefetch, g); err != nil {
+			printf("lint: %v\n", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	pkgs := flags.Args()
+	if len(pkgs) == 0 {
+		pkgs = []string{"./..."}
+	}
+	for _, pkg := range pkgs {
+		if _, err = checkers.Load(pkg); err != nil {
+			printf("lint: %v\n", err)
+			return exitFailure
+		}
+	}
+
+	if *patches != "" {
+		files, perr := g.WritePatches(*patches, pkgs...)
+		for _, f := range files {
+			printf("lint: wrote %s\n", f)
+		}
+		if perr != nil {
+			printf("lint: %v\n", perr)
+			return exitFailure
+		}
+	}
+	if *fix {
+		for _, c := range g {
+			if f, ok := c.(lint.Fixer); ok {
+				if err = f.Fix(pkgs...); err != nil {
+					printf("lint: %s: failed to fix issues: %v\n", lint.CheckerName(c), err)
+					return exitFailure
+				}
+			}
+		}
+	}
+
+	if *cache {
+		c, cerr := lint.NewCache("")
+		if cerr != nil {
+			printf("lint: %v\n", cerr)
+			return exitFailure
+		}
+		g = g.Cached(c)
+		defer func() {
+			st := c.Stats()
+			printf("lint: cache: %d hits, %d misses\n", st.Hits, st.Misses)
+		}()
+	}
+
+	code := exitOK
+	err = g.CheckParallel(0, pkgs...)
+	// Failures are written to stderr instead of being reported as issues.
+	failed := map[string]bool{}
+	for _, f := range lint.Failures(err) {
+		if ierr, ok := f.Err.(*checkers.InstallError); ok {
+			printf("lint: failed to install %s: %v\n", ierr.Bin, ierr)
+		} else {
+			printf("lint: %s: %v\n", f.Checker, f.Err)
+		}
+		for _, issue := range lint.Issues(f.Err) {
+			if issue.Checker == "" {
+				issue.Checker = f.Checker
+			}
+			failed[issue.String()] = true
+		}
+		code = exitFailure
+	}
+	var issues []checkers.Issue
+	for _, issue := range lint.Issues(err) {
+		if !failed[issue.String()] {
+			issues = append(issues, issue)
+		}
+	}
+	if err = write(stdout, checkers.Issues(issues...)); err != nil {
+		printf("lint: failed to write issues: %v\n", err)
+		return exitFailure
+	}
+	if code == exitOK && len(issues) > 0 {
+		code = exitIssues
+	}
+	return code
+}
+
+// group returns the default checkers along with enable and without disable.
+func group(enable, disable string) (lint.Group, error) {
+	disabled := map[string]bool{}
+	for _, name := range split(disable) {
+		if _, ok := checkers.LookupChecker(name); !ok {
+			return nil, fmt.Errorf("unknown checker %q, must be one of %s",
+				name, strings.Join(checkers.RegisteredCheckers(), ", "))
+		}
+		disabled[name] = true
+	}
+	var c lint.Config
+	added := map[string]bool{}
+	for _, name := range append(split(defaultNames), split(enable)...) {
+		if !disabled[name] && !added[name] {
+			added[name] = true
+			c.Checkers = append(c.Checkers, lint.CheckerConfig{Name: name})
+		}
+	}
+	return c.Group()
+}
+
+func split(names string) []string {
+	var res []string
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			res = append(res, name)
+		}
+	}
+	return res
+}
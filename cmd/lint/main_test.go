@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+type fakeChecker struct {
+	Err string
+}
+
+func (f fakeChecker) Check(pkgs ...string) error {
+	switch f.Err {
+	case "":
+		return nil
+	case "install":
+		return &checkers.InstallError{Bin: "fake", Err: fmt.Errorf("not found")}
+	case "tool":
+		return &checkers.ToolError{Tool: "fake", Err: checkers.Error("panic: crashed")}
+	default:
+		return checkers.Error(f.Err)
+	}
+}
+
+var fixed bool
+
+// fixableChecker reports an issue until it is fixed.
+type fixableChecker struct{}
+
+func (fixableChecker) Check(pkgs ...string) error {
+	if fixed {
+		return nil
+	}
+	return checkers.Error("a.go:1: fixable")
+}
+
+func (fixableChecker) Fix(pkgs ...string) error {
+	fixed = true
+	return nil
+}
+
+func (fixableChecker) Patch(pkgs ...string) (string, error) {
+	if fixed {
+		return "", nil
+	}
+	return "patch", nil
+}
+
+func init() {
+	checkers.RegisterChecker("fixable", fixableChecker{})
+	checkers.RegisterChecker("clean", fakeChecker{})
+	checkers.RegisterChecker("findings", fakeChecker{Err: "main.go:1:2: bad <code>"})
+	checkers.RegisterChecker("broken", fakeChecker{Err: "install"})
+	checkers.RegisterChecker("crashed", fakeChecker{Err: "tool"})
+}
+
+func TestRun(t *testing.T) {
+	disable := "-disable=" + defaultNames
+	for _, test := range []struct {
+		args           []string
+		code           int
+		stdout, stderr string
+	}{
+		{[]string{disable, "-enable=clean", "."}, exitOK, "", ""},
+		{[]string{disable, "-enable=clean,findings", "."}, exitIssues, "main.fakeChecker: main.go:1:2: bad <code>\n", ""},
+		{[]string{disable, "-enable=findings", "-format=json", "."}, exitIssues, `"message": "bad <code>"`, ""},
+		{[]string{disable, "-enable=findings", "-format=checkstyle", "."}, exitIssues, `message="bad &lt;code&gt;"`, ""},
+		{[]string{disable, "-enable=broken,findings", "."}, exitFailure, "main.go:1:2", "lint: failed to install fake: not found\n"},
+		{[]string{disable, "-enable=crashed", "."}, exitFailure, "", "lint: main.fakeChecker: panic: crashed\n"},
+		{[]string{disable, "-enable=unknown", "."}, exitFailure, "", `lint: unknown checker "unknown", must be one of `},
+		{[]string{"-disable=unknown", "."}, exitFailure, "", `lint: unknown checker "unknown", must be one of `},
+		{[]string{"-format=xml", "."}, exitFailure, "", `lint: unknown format "xml", must be one of checkstyle, json`},
+		{[]string{"-unknown"}, exitFailure, "", "flag provided but not defined: -unknown"},
+		{[]string{disable, "-enable=clean", "./missing"}, exitFailure, "", "lint: failed read sub packages: ./missing"},
+	} {
+		var stdout, stderr bytes.Buffer
+		code := run(test.args, &stdout, &stderr)
+		if code != test.code || !strings.Contains(stdout.String(), test.stdout) || !strings.Contains(stderr.String(), test.stderr) {
+			t.Errorf("%v: expected %d, %q, %q, got %d, %q, %q", test.args,
+				test.code, test.stdout, test.stderr, code, stdout.String(), stderr.String())
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	g, err := group("dupl,clean,clean", "golint,gosimple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range g {
+		names = append(names, fmt.Sprintf("%T", c))
+	}
+	expected := "gofmt.Check govet.Check gostaticcheck.Check errcheck.Check dupl.Check main.fakeChecker"
+	if strings.Join(names, " ") != expected {
+		t.Errorf("expected %s, got %v", expected, names)
+	}
+}
+
+func TestCache(t *testing.T) {
+	t.Setenv("LINT_CACHE", t.TempDir())
+	disable := "-disable=" + defaultNames
+	for _, expected := range []string{"0 hits, 1 misses", "1 hits, 0 misses"} {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{disable, "-enable=findings", "-cache", "."}, &stdout, &stderr)
+		if code != exitIssues || stdout.String() != "main.fakeChecker: main.go:1:2: bad <code>\n" ||
+			stderr.String() != "lint: cache: "+expected+"\n" {
+			t.Errorf("unexpected result %d: %q %q", code, stdout.String(), stderr.String())
+		}
+	}
+}
+
+func TestFix(t *testing.T) {
+	dir := t.TempDir()
+
+	disable := "-disable=" + defaultNames
+	var stdout, stderr bytes.Buffer
+	code := run([]string{disable, "-enable=fixable", "-patches", dir, "."}, &stdout, &stderr)
+	patch := filepath.Join(dir, "main.fixableChecker.patch")
+	if code != exitIssues || stderr.String() != "lint: wrote "+patch+"\n" {
+		t.Fatalf("unexpected result %d: %s %s", code, stdout.String(), stderr.String())
+	}
+	if data, err := ioutil.ReadFile(patch); err != nil || string(data) != "patch" {
+		t.Fatalf("unexpected patch %s: %v", data, err)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{disable, "-enable=fixable", "-fix", "."}, &stdout, &stderr)
+	if code != exitOK || !fixed || stdout.String() != "" || stderr.String() != "" {
+		t.Fatalf("unexpected result %d: %s %s", code, stdout.String(), stderr.String())
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	defer func(offline bool) { checkers.Offline = offline }(checkers.Offline)
+	checkers.Offline = true
+	t.Setenv("GOPROXY", "https://proxy.golang.org")
+	t.Setenv("GOFLAGS", "")
+
+	disable := "-disable=" + defaultNames
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{disable, "-enable=clean", "-prefetch", t.TempDir()}, &stdout, &stderr); code != exitOK || stderr.Len() > 0 {
+		t.Errorf("unexpected result %d: %q", code, stderr.String())
+	}
+	stderr.Reset()
+	code := run([]string{"-disable=gofmt,govet,gosimple,gostaticcheck,errcheck", "-prefetch", t.TempDir()}, &stdout, &stderr)
+	if code != exitFailure || !strings.Contains(stderr.String(), "go install golang.org/x/lint/golint@") || stdout.Len() > 0 {
+		t.Errorf("unexpected result %d: %q %q", code, stdout.String(), stderr.String())
+	}
+}
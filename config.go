@@ -0,0 +1,387 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/surullabs/lint/checkers"
+
+	// Register the checkers that are not part of Default. dupl imports lint
+	// and must be imported by users of the dupl checker instead.
+	_ "github.com/surullabs/lint/aligncheck"
+	_ "github.com/surullabs/lint/goimports"
+	_ "github.com/surullabs/lint/gometalinter"
+	_ "github.com/surullabs/lint/layers"
+	_ "github.com/surullabs/lint/rules"
+	_ "github.com/surullabs/lint/structcheck"
+	_ "github.com/surullabs/lint/varcheck"
+)
+
+// CheckerConfig configures a single Checker in a Config.
+type CheckerConfig struct {
+	// Name is the name the checker is registered with using
+	// checkers.RegisterChecker, such as govet or errcheck. All checkers in this
+	// repository other than dupl are registered by importing lint. dupl is
+	// registered by importing github.com/surullabs/lint/dupl.
+	Name string `yaml:"name"`
+	// Options sets fields of the checker. Keys are matched against field names
+	// ignoring case, as done by encoding/json.
+	Options map[string]interface{} `yaml:"options,omitempty"`
+}
+
+// Override changes the checkers applied to packages in a directory.
+type Override struct {
+	// Dir is the directory the override applies to, relative to the
+	// directory containing the configuration file. It applies to packages in
+	// sub directories as well, unless they have an override of their own.
+	Dir string `yaml:"dir"`
+	// Checkers are added to the checkers of the parent configuration. A
+	// checker with the same name as an inherited checker replaces it.
+	Checkers []CheckerConfig `yaml:"checkers,omitempty"`
+	// Disable lists the names of inherited checkers that are not applied.
+	Disable []string `yaml:"disable,omitempty"`
+	// Skip holds regular expressions, in addition to the inherited ones, for
+	// issues that are skipped.
+	Skip []string `yaml:"skip,omitempty"`
+}
+
+// Config describes the checkers to apply and the packages to check. It is
+// usually read from a .lint.yaml file using LoadConfig, which allows a single
+// test to check a whole repository.
+//
+//    packages: ["./..."]
+//    checkers:
+//      - name: gofmt
+//      - name: govet
+//      - name: dupl
+//        options: {threshold: 25}
+//    skip:
+//      - '\.pb\.go'
+//    overrides:
+//      - dir: legacy
+//        disable: [golint]
+//
+// See Check for details on how it is applied.
+type Config struct {
+	// Dir is the directory that relative packages and override directories
+	// are resolved against. LoadConfig sets it to the directory of the file.
+	Dir string `yaml:"-"`
+	// Packages lists the packages to check. It defaults to ./...
+	Packages []string `yaml:"packages,omitempty"`
+	// Checkers lists the checkers to apply in order.
+	Checkers []CheckerConfig `yaml:"checkers"`
+	// Skip holds regular expressions for issues that are skipped, as used by
+	// RegexpMatch.
+	Skip []string `yaml:"skip,omitempty"`
+	// Overrides change the checkers for packages in specific directories.
+	Overrides []Override `yaml:"overrides,omitempty"`
+}
+
+// LoadConfig reads a Config from the YAML or JSON file at path and verifies
+// that all checkers it names are registered and their options are valid.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %v", err)
+	}
+	c := &Config{}
+	if err = yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %v", path, err)
+	}
+	if c.Dir, err = filepath.Abs(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("failed to find config directory: %v", err)
+	}
+	if _, err = c.scopes(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Group returns the Group applied to packages that are not part of an Override.
+func (c *Config) Group() (Group, error) {
+	return newGroup(c.Checkers)
+}
+
+// Skippers returns the Skippers applied to issues in packages that are not
+// part of an Override.
+func (c *Config) Skippers() []Skipper {
+	return skippers(c.Skip)
+}
+
+// Check applies the configured checkers to the configured packages and skips
+// the configured issues. Without overrides this is equivalent to
+//
+//    g, _ := c.Group()
+//    lint.Skip(g.Check(c.Packages...), c.Skippers()...)
+//
+// With overrides each package is checked by the checkers of the innermost
+// Override whose directory contains it, or of the Config if there are none.
+// The returned error is described in Group.Check.
+func (c *Config) Check() error {
+	scopes, err := c.scopes()
+	if err != nil {
+		return err
+	}
+	pkgs := c.packages()
+	if len(scopes) == 1 {
+		return Skip(scopes[0].group.Check(pkgs...), scopes[0].skippers...)
+	}
+	if err = assignPackages(scopes, pkgs); err != nil {
+		return err
+	}
+	var all []checkers.Issue
+	var failures []Failure
+	for _, s := range scopes {
+		if len(s.pkgs) > 0 {
+			err = Skip(s.group.Check(s.pkgs...), s.skippers...)
+			all, failures = append(all, Issues(err)...), append(failures, Failures(err)...)
+		}
+	}
+	return groupIssues(all, failures)
+}
+
+// scope holds the checkers applied to the packages in dir.
+type scope struct {
+	dir      string
+	group    Group
+	skippers []Skipper
+	pkgs     []string
+}
+
+// scopes returns a scope for the Config followed by one for each Override.
+func (c *Config) scopes() ([]*scope, error) {
+	g, err := newGroup(c.Checkers)
+	if err != nil {
+		return nil, err
+	}
+	if err = validPatterns(c.Skip); err != nil {
+		return nil, err
+	}
+	res := []*scope{{dir: canonicalPaths{}.get(c.Dir), group: g, skippers: skippers(c.Skip)}}
+	for _, o := range c.Overrides {
+		s, err := c.overrideScope(o)
+		if err != nil {
+			return nil, fmt.Errorf("override %s: %v", o.Dir, err)
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// overrideScope creates the scope for o. Overrides inherit from the Config
+// rather than from enclosing overrides, so that each can be read on its own.
+func (c *Config) overrideScope(o Override) (*scope, error) {
+	if o.Dir == "" {
+		return nil, fmt.Errorf("dir is required")
+	}
+	disabled := map[string]bool{}
+	for _, name := range o.Disable {
+		disabled[name] = true
+	}
+	for _, cc := range o.Checkers {
+		disabled[cc.Name] = true
+	}
+	var configs []CheckerConfig
+	for _, cc := range c.Checkers {
+		if !disabled[cc.Name] {
+			configs = append(configs, cc)
+		}
+	}
+	g, err := newGroup(append(configs, o.Checkers...))
+	if err != nil {
+		return nil, err
+	}
+	skip := append(append([]string{}, c.Skip...), o.Skip...)
+	if err = validPatterns(skip); err != nil {
+		return nil, err
+	}
+	dir := o.Dir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(c.Dir, dir)
+	}
+	return &scope{dir: canonicalPaths{}.get(dir), group: g, skippers: skippers(skip)}, nil
+}
+
+// packages returns the configured packages with relative paths resolved
+// against Dir, and made relative to the current directory.
+func (c *Config) packages() []string {
+	pkgs := c.Packages
+	if len(pkgs) == 0 {
+		pkgs = []string{"./..."}
+	}
+	wd, err := os.Getwd()
+	if err != nil || c.Dir == "" {
+		return pkgs
+	}
+	res := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		res[i] = pkg
+		if pkg != "." && pkg != ".." && !strings.HasPrefix(pkg, "./") && !strings.HasPrefix(pkg, "../") {
+			continue
+		}
+		rel, rerr := filepath.Rel(wd, filepath.Join(c.Dir, pkg))
+		if rerr != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, "../") && rel != ".." {
+			rel = "./" + rel
+		}
+		if strings.HasSuffix(pkg, "/...") && !strings.HasSuffix(rel, "/...") {
+			rel += "/..."
+		}
+		res[i] = strings.TrimSuffix(rel, "/.")
+	}
+	return res
+}
+
+// assignPackages expands pkgs and assigns each package to the scope with the
+// longest directory containing it. Packages are assigned using their path
+// relative to the current directory, since packages outside the GOPATH do not
+// have an import path in GOPATH mode.
+func assignPackages(scopes []*scope, pkgs []string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to find cwd: %v", err)
+	}
+	paths := canonicalPaths{}
+	wd = paths.get(wd)
+	seen := map[string]bool{}
+	for _, pkg := range pkgs {
+		p, err := checkers.Load(pkg)
+		if err != nil {
+			return fmt.Errorf("failed to load pkg info: %s: %v", pkg, err)
+		}
+		for _, dir := range p.Dirs {
+			if dir = paths.get(dir); seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			var match *scope
+			for _, s := range scopes {
+				if within(s.dir, dir) && (match == nil || len(s.dir) > len(match.dir)) {
+					match = s
+				}
+			}
+			if match == nil {
+				match = scopes[0]
+			}
+			rel, err := relativePackage(wd, dir)
+			if err != nil {
+				return err
+			}
+			match.pkgs = append(match.pkgs, rel)
+		}
+	}
+	return nil
+}
+
+// relativePackage returns the relative package path of dir from wd, such as
+// ./pkg or ../pkg.
+func relativePackage(wd, dir string) (string, error) {
+	rel, err := filepath.Rel(wd, dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to find path of %s: %v", dir, err)
+	}
+	if rel = filepath.ToSlash(rel); rel != "." && rel != ".." && !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel, nil
+}
+
+func within(root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func skippers(patterns []string) []Skipper {
+	if len(patterns) == 0 {
+		return nil
+	}
+	return []Skipper{RegexpMatch(patterns...)}
+}
+
+func validPatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid skip pattern: %v", err)
+		}
+	}
+	return nil
+}
+
+// newGroup creates a Group containing the configured checkers.
+func newGroup(configs []CheckerConfig) (Group, error) {
+	var g Group
+	for _, cc := range configs {
+		c, err := newChecker(cc)
+		if err != nil {
+			return nil, err
+		}
+		g = append(g, c)
+	}
+	return g, nil
+}
+
+// newChecker creates a copy of the checker registered as cc.Name with the
+// configured options set.
+func newChecker(cc CheckerConfig) (Checker, error) {
+	registered, ok := checkers.LookupChecker(cc.Name)
+	if !ok {
+		return nil, fmt.Errorf("unknown checker %q, must be one of %s",
+			cc.Name, strings.Join(checkers.RegisteredCheckers(), ", "))
+	}
+	v := reflect.New(reflect.TypeOf(registered))
+	v.Elem().Set(reflect.ValueOf(registered))
+	if len(cc.Options) > 0 {
+		data, err := json.Marshal(jsonValue(cc.Options))
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid options: %v", cc.Name, err)
+		}
+		dec := json.NewDecoder(strings.NewReader(string(data)))
+		dec.DisallowUnknownFields()
+		if err = dec.Decode(v.Interface()); err != nil {
+			return nil, fmt.Errorf("%s: invalid options: %v", cc.Name, err)
+		}
+	}
+	c, ok := v.Elem().Interface().(Checker)
+	if !ok {
+		return nil, fmt.Errorf("%s: %T is not a Checker", cc.Name, registered)
+	}
+	return c, nil
+}
+
+// jsonValue converts the maps created by yaml, which have interface{} keys,
+// into maps that can be encoded as JSON.
+func jsonValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, val := range t {
+			m[fmt.Sprint(k)] = jsonValue(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for k, val := range t {
+			m[k] = jsonValue(val)
+		}
+		return m
+	case []interface{}:
+		res := make([]interface{}, len(t))
+		for i, val := range t {
+			res[i] = jsonValue(val)
+		}
+		return res
+	default:
+		return v
+	}
+}
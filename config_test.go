@@ -0,0 +1,185 @@
+package lint_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+	"github.com/surullabs/lint/errcheck"
+	"github.com/surullabs/lint/govet"
+)
+
+// recordChecker reports an issue naming each package it checks.
+type recordChecker struct {
+	Label string
+}
+
+func (r recordChecker) Check(pkgs ...string) error {
+	var issues []checkers.Issue
+	for _, pkg := range pkgs {
+		issues = append(issues, checkers.Issue{Raw: r.Label + " " + path.Base(pkg)})
+	}
+	return checkers.Issues(issues...)
+}
+
+// failChecker fails to run.
+type failChecker struct{}
+
+func (failChecker) Check(pkgs ...string) error {
+	return &checkers.ToolError{Tool: "fail", Err: fmt.Errorf("fail crashed")}
+}
+
+func init() {
+	checkers.RegisterChecker("record", recordChecker{Label: "default"})
+	checkers.RegisterChecker("fail", failChecker{})
+}
+
+func writeConfig(t *testing.T, dir, config string) string {
+	file := filepath.Join(dir, ".lint.yaml")
+	if err := ioutil.WriteFile(file, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := lint.LoadConfig(writeConfig(t, dir, `
+checkers:
+  - name: govet
+  - name: errcheck
+    options: {blank: true, Tags: "a b"}
+  - name: record
+skip: ['a\.go']
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := c.Group()
+	assert(t, err == nil, fmt.Sprint(err))
+	expected := lint.Group{govet.Shadow, errcheck.Check{Blank: true, Tags: "a b"}, recordChecker{Label: "default"}}
+	assert(t, reflect.DeepEqual(g, expected), fmt.Sprintf("unexpected group %#v", g))
+	assert(t, len(c.Skippers()) == 1 && c.Skippers()[0].Skip("x: a.go:1: y"), "skip pattern not applied")
+
+	for _, test := range []struct {
+		config, err string
+	}{
+		{"checkers: [{name: unknown}]", `unknown checker "unknown", must be one of `},
+		{"checkers: [{name: errcheck, options: {blanks: true}}]", `errcheck: invalid options: json: unknown field "blanks"`},
+		{"checkers: [{name: errcheck, options: {blank: 1}}]", "errcheck: invalid options: json: cannot unmarshal"},
+		{"skip: ['(']", "invalid skip pattern"},
+		{"overrides: [{disable: [govet]}]", "override : dir is required"},
+		{"overrides: [{dir: a, checkers: [{name: unknown}]}]", `override a: unknown checker "unknown"`},
+		{"checker: []", "field checker not found"},
+	} {
+		_, err = lint.LoadConfig(writeConfig(t, dir, test.config))
+		assert(t, err != nil && strings.Contains(err.Error(), test.err), fmt.Sprintf("%s: expected %q, got %v", test.config, test.err, err))
+	}
+	_, err = lint.LoadConfig(filepath.Join(dir, "missing.yaml"))
+	assert(t, err != nil && strings.HasPrefix(err.Error(), "failed to read config: "), fmt.Sprint(err))
+}
+
+func TestRegisteredCheckers(t *testing.T) {
+	registered := map[string]bool{}
+	for _, name := range checkers.RegisteredCheckers() {
+		registered[name] = true
+	}
+	// dupl is registered by lint_test, which imports it.
+	for _, name := range []string{
+		"aligncheck", "dupl", "errcheck", "gofmt", "goimports", "golint", "gometalinter",
+		"gosimple", "gostaticcheck", "govet", "layers", "rules", "structcheck", "varcheck",
+	} {
+		assert(t, registered[name], fmt.Sprintf("%s is not registered", name))
+	}
+}
+
+func TestConfigCheck(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkg := range []string{"", "a", "legacy", "legacy/b", "legacy/c", "legacy/new"} {
+		pkgDir := filepath.Join(dir, "root", pkg)
+		if err = os.MkdirAll(pkgDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(filepath.Join(pkgDir, "a.go"), []byte("package a\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+	defer checkers.Unload("./root/...")
+
+	check := func(config string) []string {
+		c, err := lint.LoadConfig(writeConfig(t, filepath.Join(dir, "root"), config))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res []string
+		for _, issue := range lint.Issues(c.Check()) {
+			res = append(res, issue.String())
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	// Without overrides the packages are passed on unchanged, apart from being
+	// made relative to the current directory.
+	res := check(`
+checkers: [{name: record}]
+packages: [./..., ./a]
+skip: [' a$']
+`)
+	assert(t, reflect.DeepEqual(res, []string{"lint_test.recordChecker: default ..."}), fmt.Sprint(res))
+
+	res = check(`
+checkers: [{name: record}]
+skip: [' b$']
+overrides:
+  - dir: legacy
+    checkers: [{name: record, options: {label: legacy}}]
+    skip: [' legacy$']
+  - dir: legacy/new
+    disable: [record]
+`)
+	expected := []string{
+		"lint_test.recordChecker: default a",
+		"lint_test.recordChecker: default root",
+		"lint_test.recordChecker: legacy c",
+	}
+	assert(t, reflect.DeepEqual(res, expected), fmt.Sprint(res))
+
+	// Failures in overrides are kept. The output of the failure is reported
+	// since all other issues in the override are skipped.
+	c, err := lint.LoadConfig(writeConfig(t, filepath.Join(dir, "root"), `
+checkers: [{name: record}]
+overrides:
+  - dir: legacy
+    checkers: [{name: fail}]
+    disable: [record]
+    skip: ['crashed']
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Check()
+	failures := lint.Failures(err)
+	assert(t, len(failures) == 1 && failures[0].Checker == "lint_test.failChecker", fmt.Sprintf("%#v", failures))
+	assert(t, strings.HasSuffix(err.Error(), "\nlint_test.failChecker: fail crashed"), fmt.Sprint(err))
+}
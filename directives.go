@@ -0,0 +1,298 @@
+package lint
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+// directive is a single //lint:ignore or //lint:file-ignore comment.
+type directive struct {
+	file     string
+	line     int
+	kind     string
+	checkers []string
+	used     bool
+}
+
+const (
+	ignoreDirective     = "lint:ignore"
+	fileIgnoreDirective = "lint:file-ignore"
+	// directiveChecker is the Checker of issues reported for directives.
+	directiveChecker = "lint.Directive"
+)
+
+// staticcheckID matches the check IDs of staticcheck, such as SA4006.
+// staticcheck honors directives naming them itself and reports those that
+// suppress nothing.
+var staticcheckID = regexp.MustCompile(`^[A-Z]+[0-9]+$`)
+
+// matches returns true if d applies to issue. Directives name checkers by
+// their type, such as govet.Check, or by their package, such as govet. They can
+// also name the RuleID of an issue, such as SA4006.
+func (d *directive) matches(issue checkers.Issue) bool {
+	if d.names(issue.Checker) {
+		return true
+	}
+	for _, c := range d.checkers {
+		if issue.RuleID != "" && c == issue.RuleID {
+			return true
+		}
+	}
+	return false
+}
+
+// names returns true if d names checker by its type or its package.
+func (d *directive) names(checker string) bool {
+	pkg := checker
+	if i := strings.Index(checker, "."); i >= 0 {
+		pkg = checker[:i]
+	}
+	for _, c := range d.checkers {
+		if c == checker || c == pkg {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipDirectives skips issues in err which are suppressed by directives in the
+// source files of pkgs. A directive is a comment of the form
+//
+//    //lint:ignore Checker[,Checker...] reason
+//
+// which skips issues reported by any of the checkers on the line of the comment
+// or the line following it, or
+//
+//    //lint:file-ignore Checker[,Checker...] reason
+//
+// which skips all issues reported by the checkers in the file containing it. A
+// checker is named by its type, as reported by Group.Check, or by its package.
+// For example, both govet.Check and govet match issues from govet.Check. The
+// RuleID of an issue, such as SA4006 for staticcheck, may be named instead.
+//
+// Directives must include a reason. An issue is reported for each malformed
+// directive and for each directive that did not skip any issue, so that stale
+// directives are removed. Directives naming only staticcheck checks such as
+// SA4006 are not reported, since staticcheck honors and reports them itself.
+// err should therefore contain issues from every checker named in directives.
+// See Issues for details on how issues are extracted from err.
+//
+// Group.Check, Test and the lint command already apply directives. Use
+// SkipDirectives for errors returned by other Checkers.
+func SkipDirectives(err error, pkgs ...string) error {
+	d, derr := loadDirectives(pkgs)
+	if derr != nil {
+		return derr
+	}
+	return checkers.Issues(d.apply(Issues(err), nil)...)
+}
+
+// directives holds the directives in a set of packages.
+type directives struct {
+	all       []*directive
+	byFile    map[string][]*directive
+	malformed []checkers.Issue
+	paths     canonicalPaths
+}
+
+// loadDirectives reads the directives in the source files of pkgs.
+func loadDirectives(pkgs []string) (*directives, error) {
+	files, err := directiveFiles(pkgs)
+	if err != nil {
+		return nil, err
+	}
+	d := &directives{byFile: map[string][]*directive{}, paths: canonicalPaths{}}
+	for _, file := range files {
+		found, malformed, rerr := readDirectives(file)
+		if rerr != nil {
+			return nil, rerr
+		}
+		d.all, d.malformed = append(d.all, found...), append(d.malformed, malformed...)
+		for _, f := range found {
+			d.byFile[f.file] = append(d.byFile[f.file], f)
+		}
+	}
+	return d, nil
+}
+
+// apply returns an issue for each malformed directive, followed by the issues
+// that are not suppressed by a directive and the unused directives described in
+// unused.
+//
+// Malformed directives already in issues, such as those reported by a nested
+// Group, are not repeated.
+func (d *directives) apply(issues []checkers.Issue, ran []string) []checkers.Issue {
+	reported := map[string]bool{}
+	for _, issue := range issues {
+		if issue.Checker == directiveChecker {
+			reported[issue.Raw] = true
+		}
+	}
+	var res []checkers.Issue
+	for _, issue := range d.malformed {
+		if !reported[issue.Raw] {
+			res = append(res, issue)
+		}
+	}
+	return append(append(res, d.skip(issues)...), d.unused(ran)...)
+}
+
+// skip returns the issues that are not suppressed by a directive.
+func (d *directives) skip(issues []checkers.Issue) []checkers.Issue {
+	var res []checkers.Issue
+	for _, issue := range issues {
+		if !skipDirective(issue, d.byFile[d.paths.get(issue.File)]) {
+			res = append(res, issue)
+		}
+	}
+	return res
+}
+
+// unused returns an issue for each directive that has not skipped an issue. If
+// ran is not nil only directives naming one of the checkers in ran are
+// reported, since directives for other checkers cannot have been used.
+func (d *directives) unused(ran []string) []checkers.Issue {
+	var res []checkers.Issue
+	for _, dir := range d.all {
+		if dir.used || !dir.reportable(ran) {
+			continue
+		}
+		msg := fmt.Sprintf("unused %s directive for %s", dir.kind, strings.Join(dir.checkers, ","))
+		res = append(res, directiveIssue(dir.file, dir.line, msg))
+	}
+	return res
+}
+
+// reportable returns true if d can be reported as unused after checking with
+// the checkers in ran, or all checkers if ran is nil.
+func (d *directive) reportable(ran []string) bool {
+	if ran != nil {
+		for _, checker := range ran {
+			if d.names(checker) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, c := range d.checkers {
+		if !staticcheckID.MatchString(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func skipDirective(issue checkers.Issue, directives []*directive) bool {
+	skipped := false
+	for _, d := range directives {
+		if !d.matches(issue) {
+			continue
+		}
+		if d.kind == fileIgnoreDirective || (issue.Line > 0 && (issue.Line == d.line || issue.Line == d.line+1)) {
+			d.used, skipped = true, true
+		}
+	}
+	return skipped
+}
+
+func directiveIssue(file string, line int, msg string) checkers.Issue {
+	return checkers.Issue{
+		Checker:  directiveChecker,
+		File:     file,
+		Line:     line,
+		Severity: checkers.SeverityError,
+		Message:  msg,
+		Raw:      fmt.Sprintf("%s:%d: %s", file, line, msg),
+	}
+}
+
+// directiveFiles lists all Go files, including tests, in pkgs.
+func directiveFiles(pkgs []string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	paths := canonicalPaths{}
+	for _, pkg := range pkgs {
+		p, err := checkers.Load(pkg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load files for %s: %v", pkg, err)
+		}
+		for _, f := range p.Files {
+			if f = paths.get(f); strings.HasSuffix(f, ".go") && !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	return files, nil
+}
+
+// readDirectives returns the directives in file along with an issue for each
+// malformed directive.
+func readDirectives(file string) ([]*directive, []checkers.Issue, error) {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read directives: %v", err)
+	}
+	fset := token.NewFileSet()
+	var s scanner.Scanner
+	// Errors are ignored since they are reported by the linters.
+	s.Init(fset.AddFile(file, -1, len(src)), src, nil, scanner.ScanComments)
+	var directives []*directive
+	var malformed []checkers.Issue
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			return directives, malformed, nil
+		}
+		if tok != token.COMMENT || !strings.HasPrefix(lit, "//lint:") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(lit, "//"))
+		kind, line := fields[0], fset.Position(pos).Line
+		if kind != ignoreDirective && kind != fileIgnoreDirective {
+			continue
+		}
+		if len(fields) < 3 {
+			msg := fmt.Sprintf("malformed %s directive: expected //%s Checker reason", kind, kind)
+			malformed = append(malformed, directiveIssue(file, line, msg))
+			continue
+		}
+		directives = append(directives, &directive{
+			file:     file,
+			line:     line,
+			kind:     kind,
+			checkers: strings.Split(fields[1], ","),
+		})
+	}
+}
+
+// canonicalPaths caches absolute paths with symbolic links resolved, so
+// that paths reported by different tools can be compared.
+type canonicalPaths map[string]string
+
+func (c canonicalPaths) get(path string) string {
+	if path == "" {
+		return ""
+	}
+	if res, ok := c[path]; ok {
+		return res
+	}
+	res, err := filepath.Abs(path)
+	if err != nil {
+		res = path
+	} else if resolved, rerr := filepath.EvalSymlinks(res); rerr == nil {
+		res = resolved
+	} else if dir, derr := filepath.EvalSymlinks(filepath.Dir(res)); derr == nil {
+		// Files reported by linters may no longer exist.
+		res = filepath.Join(dir, filepath.Base(res))
+	}
+	c[path] = res
+	return res
+}
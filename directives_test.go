@@ -0,0 +1,118 @@
+package lint_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+)
+
+const directivesSrc = `package src
+
+//lint:file-ignore golint generated code
+func a() {
+	f() //lint:ignore lint_test.checkFn,errcheck errors are not possible
+	//lint:ignore errcheck errors are not possible
+	g()
+	s := "//lint:ignore errcheck inside a string"
+	//lint:ignore checkFn
+	//lint:ignore gosimple never used
+}
+`
+
+// inDirectivesDir writes src to src.go in a temporary directory and changes to
+// it, returning the path of the file and a function restoring the working
+// directory.
+func inDirectivesDir(t *testing.T, src string) (string, func()) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "src.go")
+	if err = ioutil.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	checkers.Unload(".")
+	return file, func() {
+		_ = os.Chdir(wd)
+		checkers.Unload(".")
+	}
+}
+
+func TestSkipDirectives(t *testing.T) {
+	src, restore := inDirectivesDir(t, directivesSrc)
+	defer restore()
+
+	err := lint.SkipDirectives(checkers.Issues(
+		checkers.Issue{Checker: "golint.Check", File: "src.go", Line: 4, Raw: "golint"},
+		checkers.Issue{Checker: "lint_test.checkFn", File: "src.go", Line: 5, Raw: "same line"},
+		checkers.Issue{Checker: "errcheck.Check", File: src, Line: 7, Raw: "line below"},
+		checkers.Issue{Checker: "errcheck.Check", File: "src.go", Line: 8, Raw: "string"},
+		checkers.Issue{Checker: "govet.Check", File: "src.go", Line: 5, Raw: "other checker"},
+		checkers.Issue{Checker: "errcheck.Check", Raw: "no file"},
+	), ".")
+	expected := []string{
+		"lint.Directive: " + src + ":9: malformed lint:ignore directive: expected //lint:ignore Checker reason",
+		"errcheck.Check: string",
+		"govet.Check: other checker",
+		"errcheck.Check: no file",
+		"lint.Directive: " + src + ":10: unused lint:ignore directive for gosimple",
+	}
+	assert(t, err != nil && reflect.DeepEqual(err.(interface {
+		Errors() []string
+	}).Errors(), expected), fmt.Sprintf("%v", err))
+
+	err = lint.SkipDirectives(nil, ".")
+	assert(t, len(lint.Issues(err)) == 5, fmt.Sprintf("%v", err))
+
+	err = lint.SkipDirectives(nil, "./missing")
+	assert(t, err != nil, "expected an error for a missing package")
+}
+
+const groupDirectivesSrc = `package src
+
+func a() {
+	f() //lint:ignore SA4006 suppressed by rule
+	//lint:ignore lint_test.checkFn suppressed by checker
+	g()
+	//lint:ignore SA1000 handled by staticcheck
+	//lint:ignore lint_test.checkFn unused
+	//lint:ignore govet not part of the group
+}
+`
+
+func TestGroupDirectives(t *testing.T) {
+	src, restore := inDirectivesDir(t, groupDirectivesSrc)
+	defer restore()
+
+	err := lint.Group{checkFn(func(...string) error {
+		return checkers.Issues(
+			checkers.Issue{File: "src.go", Line: 4, RuleID: "SA4006", Raw: "rule"},
+			checkers.Issue{File: "src.go", Line: 6, Raw: "checker"},
+			checkers.Issue{File: "src.go", Line: 7, Raw: "reported"},
+		)
+	})}.Check(".")
+	expected := []string{
+		"lint_test.checkFn: reported",
+		"lint.Directive: " + src + ":8: unused lint:ignore directive for lint_test.checkFn",
+	}
+	assert(t, err != nil && reflect.DeepEqual(err.(interface {
+		Errors() []string
+	}).Errors(), expected), fmt.Sprintf("%v", err))
+
+	// Directives reported by a nested Group are not reported again.
+	err = lint.Group{lint.Group{checkFn(func(...string) error { return nil })}}.Check(".")
+	assert(t, len(lint.Issues(err)) == 2, fmt.Sprintf("%v", err))
+}
@@ -19,6 +19,25 @@ type Check struct {
 	Threshold int
 }
 
+// Binary is the pinned version of dupl that is installed and run.
+var Binary = checkers.Binary{
+	Name:    "dupl",
+	Package: "github.com/mibk/dupl",
+	Module:  "github.com/mibk/dupl",
+	Version: "v1.0.0",
+}
+
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "dupl",
+		InformationURI: "https://github.com/mibk/dupl",
+		Rules: []checkers.Rule{
+			{ID: "dupl", Description: "Duplicated code", HelpURI: "https://github.com/mibk/dupl"},
+		},
+	})
+	checkers.RegisterChecker("dupl", Check{})
+}
+
 var (
 	foundRE = regexp.MustCompile(`found [0-9]+ clones:`)
 	finalRE = regexp.MustCompile(`Found total [0-9]+ clone groups.`)
@@ -56,7 +75,7 @@ func (c Check) Check(pkgs ...string) error {
 	if err != nil {
 		return err
 	}
-	bin, err := checkers.InstallMissing("dupl", "github.com/mibk/dupl", "github.com/mibk/dupl")
+	bin, err := Binary.Install()
 	if err != nil {
 		return err
 	}
@@ -93,3 +112,9 @@ func (c Check) Check(pkgs ...string) error {
 	}
 	return checkers.Error(errs...)
 }
+
+// Version returns the pinned version of dupl.
+func (Check) Version() (string, error) { return Binary.String(), nil }
+
+// Binaries returns the pinned version of dupl. It is used by lint.Prefetch.
+func (Check) Binaries() []checkers.Binary { return []checkers.Binary{Binary} }
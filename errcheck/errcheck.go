@@ -1,7 +1,19 @@
 // Package errcheck provides lint integration for the errcheck linter
 package errcheck
 
-import "github.com/surullabs/lint/checkers"
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/surullabs/lint/checkers"
+)
 
 // Check runs the errcheck linter (https://github.com/kisielk/errcheck)
 type Check struct {
@@ -11,15 +23,182 @@ type Check struct {
 	Assert bool
 	// Tags is a list of space separated build tags
 	Tags string
+	// IgnorePkg lists import paths of packages whose functions are never
+	// checked.
+	IgnorePkg []string
+	// Ignore lists pairs of the form pkg:regex. Functions in pkg whose names
+	// match regex are not checked. errcheck splits -ignore on commas and keeps
+	// one regex per package, so the regexes of a package are combined and must
+	// not contain commas. errcheck deprecates this in favour of Exclude.
+	Ignore []string
+	// Exclude lists functions whose errors need not be checked, using the
+	// syntax of errcheck exclude files. For example
+	//
+	//    fmt.Fprintf
+	//    (*os.File).Close
+	//    (*bytes.Buffer).Write
+	Exclude []string
+	// ExcludeFile is the path of an errcheck exclude file.
+	ExcludeFile string
+	// IgnoreTests disables checking of _test.go files.
+	IgnoreTests bool
+	// IgnoreGenerated disables checking of generated files.
+	IgnoreGenerated bool
+}
+
+// excludePlaceholder stands in for the exclude file generated for Exclude in
+// the arguments returned by Args.
+const excludePlaceholder = "<exclude>"
+
+// Binary is the pinned version of errcheck that is installed and run.
+var Binary = checkers.Binary{
+	Name:    "errcheck",
+	Package: "github.com/kisielk/errcheck",
+	Module:  "github.com/kisielk/errcheck",
+	Version: "v1.20.0",
+}
+
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "errcheck",
+		InformationURI: "https://github.com/kisielk/errcheck",
+		Rules: []checkers.Rule{
+			{ID: "errcheck", Description: "Unchecked errors", HelpURI: "https://github.com/kisielk/errcheck"},
+		},
+	})
+	checkers.RegisterChecker("errcheck", Check{})
 }
 
 // Check runs errcheck and returns any errors found.
 func (c Check) Check(pkgs ...string) error {
-	return checkers.Lint("errcheck", "", "github.com/kisielk/errcheck", pkgs, c.Args()...)
+	return c.CheckContext(context.Background(), pkgs...)
+}
+
+// CheckContext runs errcheck and returns any errors found. errcheck is killed
+// if ctx is done before it completes.
+func (c Check) CheckContext(ctx context.Context, pkgs ...string) error {
+	for _, ignore := range c.Ignore {
+		if strings.Contains(ignore, ",") {
+			return &checkers.ToolError{Tool: "errcheck", Err: fmt.Errorf("ignore pattern %q must not contain commas, use Exclude instead", ignore)}
+		}
+	}
+	exclude, cleanup, err := c.excludeFile()
+	if err != nil {
+		return &checkers.ToolError{Tool: "errcheck", Err: err}
+	}
+	defer cleanup()
+	return loadErrors(checkers.LintBinary(ctx, Binary, pkgs, c.args(exclude)...))
+}
+
+var (
+	loadErrorRE = regexp.MustCompile(`(?s)^error: failed to check packages: errors while loading package [^:]+: \[(.*)\]$`)
+	positionRE  = regexp.MustCompile(`(?:^|\s)((?:[A-Za-z]:[\\/])?[^\s:\[\]]+\.go:[0-9]+(?::[0-9]+)?): `)
+)
+
+// loadErrors converts the failure returned when errcheck cannot load a package,
+// such as one with syntax errors, into an issue for each located error, as
+// reported by compilers. Other failures are returned unmodified.
+func loadErrors(err error) error {
+	terr, ok := err.(*checkers.ToolError)
+	if !ok {
+		return err
+	}
+	errs, ok := terr.Err.(interface {
+		Errors() []string
+	})
+	if !ok {
+		return err
+	}
+	m := loadErrorRE.FindStringSubmatch(strings.Join(errs.Errors(), "\n"))
+	if m == nil {
+		return err
+	}
+	// Errors are separated by spaces. The compiler reports paths relative
+	// to the working directory and the type checker absolute ones, often for
+	// the same error.
+	var issues []checkers.Issue
+	seen := map[string]bool{}
+	locs := positionRE.FindAllStringSubmatchIndex(m[1], -1)
+	for i, loc := range locs {
+		end := len(m[1])
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		pos := m[1][loc[2]:loc[3]]
+		if abs, aerr := filepath.Abs(pos); aerr == nil {
+			pos = abs
+		}
+		raw := pos + ": " + strings.TrimSpace(m[1][loc[1]:end])
+		if !seen[raw] {
+			seen[raw] = true
+			issues = append(issues, checkers.ParseIssue(raw))
+		}
+	}
+	if len(issues) == 0 {
+		return err
+	}
+	return checkers.Issues(issues...)
+}
+
+// excludeFile returns the exclude file passed to errcheck. If Exclude is set, a
+// temporary file holding the contents of ExcludeFile followed by Exclude is
+// created and removed by cleanup.
+func (c Check) excludeFile() (path string, cleanup func(), err error) {
+	if len(c.Exclude) == 0 {
+		return c.ExcludeFile, func() {}, nil
+	}
+	var content []byte
+	if c.ExcludeFile != "" {
+		if content, err = ioutil.ReadFile(c.ExcludeFile); err != nil {
+			return "", nil, err
+		}
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			content = append(content, '\n')
+		}
+	}
+	content = append(content, strings.Join(c.Exclude, "\n")+"\n"...)
+	f, err := ioutil.TempFile("", "errcheck-exclude")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { _ = os.Remove(f.Name()) }
+	if _, err = f.Write(content); err == nil {
+		err = f.Close()
+	} else {
+		_ = f.Close()
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return f.Name(), cleanup, nil
 }
 
-// Args returns command line arguments used for errcheck
+// Version returns the pinned version of errcheck, followed by a hash of the
+// contents of ExcludeFile if it is set. It is part of the key used to cache
+// results, so that they change when ExcludeFile is edited.
+func (c Check) Version() (string, error) {
+	if c.ExcludeFile == "" {
+		return Binary.String(), nil
+	}
+	content, err := ioutil.ReadFile(c.ExcludeFile)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return Binary.String() + " exclude " + hex.EncodeToString(sum[:]), nil
+}
+
+// Args returns command line arguments used for errcheck. If Exclude is set the
+// exclude file, which is generated when errcheck is run, is shown as <exclude>.
 func (c Check) Args() []string {
+	if len(c.Exclude) > 0 {
+		return c.args(excludePlaceholder)
+	}
+	return c.args(c.ExcludeFile)
+}
+
+func (c Check) args(exclude string) []string {
 	var args []string
 	if c.Blank {
 		args = append(args, "-blank")
@@ -30,5 +209,52 @@ func (c Check) Args() []string {
 	if c.Tags != "" {
 		args = append(args, "-tags", c.Tags)
 	}
+	if len(c.IgnorePkg) > 0 {
+		args = append(args, "-ignorepkg", strings.Join(c.IgnorePkg, ","))
+	}
+	for _, ignore := range c.ignores() {
+		args = append(args, "-ignore", ignore)
+	}
+	if exclude != "" {
+		args = append(args, "-exclude", exclude)
+	}
+	if c.IgnoreTests {
+		args = append(args, "-ignoretests")
+	}
+	if c.IgnoreGenerated {
+		args = append(args, "-ignoregenerated")
+	}
 	return args
 }
+
+// ignores returns Ignore with the regexes of each package combined, in the
+// order packages first appear.
+func (c Check) ignores() []string {
+	var pkgs []string
+	regexes := map[string][]string{}
+	for _, ignore := range c.Ignore {
+		pkg, re := "", ignore
+		if i := strings.Index(ignore, ":"); i >= 0 {
+			pkg, re = ignore[:i], ignore[i+1:]
+		}
+		if _, ok := regexes[pkg]; !ok {
+			pkgs = append(pkgs, pkg)
+		}
+		regexes[pkg] = append(regexes[pkg], re)
+	}
+	var res []string
+	for _, pkg := range pkgs {
+		re := regexes[pkg][0]
+		if len(regexes[pkg]) > 1 {
+			re = "(" + strings.Join(regexes[pkg], ")|(") + ")"
+		}
+		if pkg != "" {
+			re = pkg + ":" + re
+		}
+		res = append(res, re)
+	}
+	return res
+}
+
+// Binaries returns the pinned version of errcheck. It is used by lint.Prefetch.
+func (Check) Binaries() []checkers.Binary { return []checkers.Binary{Binary} }
@@ -1,8 +1,15 @@
 package errcheck_test
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
 	"github.com/surullabs/lint/errcheck"
 	"github.com/surullabs/lint/testutil"
 )
@@ -26,7 +33,7 @@ sfsff
 func TestFunc() {
 }
 `),
-			Validate: testutil.Contains("expected declaration, found 'IDENT' sfsff"),
+			Validate: parseError(2, "expected declaration, found"),
 		},
 		{
 			Checker: errcheck.Check{},
@@ -67,10 +74,97 @@ func TestFunc() {
 `),
 			Validate: testutil.Contains("_ = i.(int)"),
 		},
+		{
+			Checker: errcheck.Check{Exclude: []string{"(*os.File).Close"}},
+			Content: []byte(`package errchecktest
+import (
+	"os"
+)
+
+func TestFunc() {
+	f, _ := os.Open("somefile")
+	f.Close()
+}
+`),
+			Validate: testutil.NoError,
+		},
+		{
+			Checker: errcheck.Check{IgnorePkg: []string{"os"}},
+			Content: []byte(`package errchecktest
+import (
+	"os"
+)
+
+func TestFunc() {
+	os.Remove("somefile")
+}
+`),
+			Validate: testutil.NoError,
+		},
 	},
 	)
 }
 
+// parseError returns a validator checking that err reports a parse error in
+// file.go at line as an issue, rather than as a failure of errcheck.
+func parseError(line int, msg string) func(error) error {
+	return func(err error) error {
+		if _, ok := err.(*checkers.ToolError); ok {
+			return fmt.Errorf("expected an issue, got failure %v", err)
+		}
+		for _, issue := range lint.Issues(err) {
+			if filepath.Base(issue.File) == "file.go" && issue.Line == line && strings.Contains(issue.Message, msg) {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected an issue at file.go:%d containing %q, got %v", line, msg, err)
+	}
+}
+
+func TestIgnore(t *testing.T) {
+	content := []byte(`package errchecktest
+import (
+	"os"
+)
+
+func TestFunc() {
+	os.Remove("somefile")
+	os.Chdir("somedir")
+}
+`)
+	testutil.Test(t, "errchecktest", []testutil.StaticCheckTest{
+		{Checker: errcheck.Check{Ignore: []string{"os:Remove", "os:Chdir"}}, Content: content, Validate: testutil.NoError},
+		{Checker: errcheck.Check{Ignore: []string{"os:Chdir"}}, Content: content, Validate: testutil.HasSuffix(`os.Remove("somefile")`)},
+		{Checker: errcheck.Check{Ignore: []string{"os:Re(m){1,2}ove"}}, Content: content, Validate: testutil.Contains("must not contain commas")},
+	})
+}
+
+func TestExcludeFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "excludes.txt")
+	if err := ioutil.WriteFile(file, []byte("os.Remove"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	content := []byte(`package errchecktest
+import (
+	"os"
+)
+
+func TestFunc() {
+	os.Remove("somefile")
+	f, _ := os.Open("somefile")
+	f.Close()
+}
+`)
+	testutil.Test(t, "errchecktest", []testutil.StaticCheckTest{
+		{Checker: errcheck.Check{ExcludeFile: file}, Content: content, Validate: testutil.HasSuffix("f.Close()")},
+		{Checker: errcheck.Check{ExcludeFile: file, Exclude: []string{"(*os.File).Close"}}, Content: content, Validate: testutil.NoError},
+	})
+	if _, err := os.Stat(file); err != nil {
+		t.Fatal("exclude file removed", err)
+	}
+}
+
 func TestGoErrCheckMultiFile(t *testing.T) {
 	test := testutil.StaticCheckMultiFileTest{
 		Contents: [][]byte{
@@ -107,5 +201,37 @@ func TestArgs(t *testing.T) {
 		{A: errcheck.Check{Assert: true}, Expected: []string{"-asserts"}},
 		{A: errcheck.Check{Tags: "test"}, Expected: []string{"-tags", "test"}},
 		{A: errcheck.Check{Blank: true, Assert: true}, Expected: []string{"-blank", "-asserts"}},
+		{A: errcheck.Check{IgnorePkg: []string{"fmt", "io"}}, Expected: []string{"-ignorepkg", "fmt,io"}},
+		{A: errcheck.Check{ExcludeFile: "excludes.txt"}, Expected: []string{"-exclude", "excludes.txt"}},
+		{A: errcheck.Check{ExcludeFile: "excludes.txt", Exclude: []string{"fmt.Fprintf"}}, Expected: []string{"-exclude", "<exclude>"}},
+		{A: errcheck.Check{IgnoreTests: true, IgnoreGenerated: true}, Expected: []string{"-ignoretests", "-ignoregenerated"}},
+		{
+			A:        errcheck.Check{Tags: "tag", IgnorePkg: []string{"io"}, Exclude: []string{"fmt.Fprintf"}, IgnoreTests: true},
+			Expected: []string{"-tags", "tag", "-ignorepkg", "io", "-exclude", "<exclude>", "-ignoretests"},
+		},
 	})
 }
+
+func TestVersion(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "excludes.txt")
+	if err := ioutil.WriteFile(file, []byte("os.Remove"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := errcheck.Check{ExcludeFile: file}.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(file, []byte("os.Remove\n(*os.File).Close"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	after, err := errcheck.Check{ExcludeFile: file}.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before == after {
+		t.Errorf("version %q did not change when the exclude file changed", before)
+	}
+	if _, err = (errcheck.Check{ExcludeFile: filepath.Join(t.TempDir(), "missing")}).Version(); err == nil {
+		t.Error("expected an error for a missing exclude file")
+	}
+}
@@ -0,0 +1,72 @@
+package lint
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+// Fixer is a Checker that can fix the issues it reports.
+//
+// Fix rewrites the files in pkgs, which are specified as for Check, so that
+// Check no longer reports issues that can be fixed automatically.
+type Fixer interface {
+	Checker
+	Fix(pkgs ...string) error
+}
+
+// Patcher is a Checker that can describe fixes for the issues it reports
+// without applying them.
+//
+// Patch returns a patch, in the unified diff format, that fixes the issues in
+// pkgs, or an empty string if there is nothing to fix.
+type Patcher interface {
+	Checker
+	Patch(pkgs ...string) (string, error)
+}
+
+// Fix runs Fix for each Fixer in g in the order provided and then checks pkgs
+// using g, so that only issues which could not be fixed are reported. Errors
+// returned by Fix are reported in the same way as issues. The returned error
+// is described in Check.
+func (g Group) Fix(pkgs ...string) error {
+	var issues []checkers.Issue
+	for _, c := range g {
+		if f, ok := c.(Fixer); ok {
+			issues = append(issues, namedIssues(c, f.Fix(pkgs...))...)
+		}
+	}
+	return checkers.Issues(append(issues, Issues(g.Check(pkgs...))...)...)
+}
+
+// WritePatches writes the patch returned by each Patcher in g to a file in dir
+// named after the Patcher, such as gofmt.Check.patch. No file is written for a
+// Patcher with nothing to fix. The files written are returned.
+func (g Group) WritePatches(dir string, pkgs ...string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create patch directory: %v", err)
+	}
+	var files []string
+	for _, c := range g {
+		p, ok := c.(Patcher)
+		if !ok {
+			continue
+		}
+		patch, err := p.Patch(pkgs...)
+		if err != nil {
+			return files, fmt.Errorf("%s: failed to create patch: %v", CheckerName(c), err)
+		}
+		if patch == "" {
+			continue
+		}
+		file := filepath.Join(dir, CheckerName(c)+".patch")
+		if err = ioutil.WriteFile(file, []byte(patch), 0644); err != nil {
+			return files, fmt.Errorf("failed to write patch: %v", err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
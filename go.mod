@@ -0,0 +1,13 @@
+module github.com/surullabs/lint
+
+go 1.25.0
+
+require (
+	golang.org/x/tools v0.47.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
+	golang.org/x/mod v0.37.0 // indirect
+	golang.org/x/sync v0.21.0 // indirect
+)
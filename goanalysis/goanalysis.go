@@ -0,0 +1,211 @@
+// Package goanalysis runs go/analysis Analyzers in-process as a lint Checker.
+//
+// Linters run by other checkers are separate binaries which load and type check
+// the packages again. Check loads the packages once and runs every Analyzer on
+// them, which also makes it possible to run custom analyzers without building
+// a binary.
+//
+//    lint.Group{
+//        goanalysis.Check{Analyzers: []*analysis.Analyzer{nilness.Analyzer, shadow.Analyzer}},
+//    }
+package goanalysis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+// Check implements lint.ContextChecker by running Analyzers on packages.
+//
+// Each diagnostic is reported as an issue whose RuleID is the category of the
+// diagnostic, or the name of the Analyzer if it has none. Errors loading
+// packages, along with errors returned by an Analyzer, are reported as issues
+// without a RuleID.
+type Check struct {
+	// Analyzers to run. Analyzers they require are run as well, but only the
+	// diagnostics of Analyzers are reported.
+	Analyzers []*analysis.Analyzer
+	// Tags is a list of space separated build tags
+	Tags string
+	// Tests includes the test files of packages.
+	Tests bool
+}
+
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "go/analysis",
+		InformationURI: "https://pkg.go.dev/golang.org/x/tools/go/analysis",
+		RuleURI:        "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/%s",
+	})
+}
+
+// Check runs Analyzers on pkgs.
+func (c Check) Check(pkgs ...string) error {
+	return c.CheckContext(context.Background(), pkgs...)
+}
+
+// Version returns the versions of this module and of golang.org/x/tools, which
+// holds the analysis framework and most analyzers. It is part of the key used
+// to cache results.
+func (Check) Version() (string, error) {
+	return checkers.ModuleVersion("github.com/surullabs/lint") + " " + checkers.ModuleVersion("golang.org/x/tools"), nil
+}
+
+// CheckContext runs Analyzers on pkgs. If ctx is done first, an issue reporting
+// that checking stopped is returned without waiting for the Analyzers.
+func (c Check) CheckContext(ctx context.Context, pkgs ...string) error {
+	if len(c.Analyzers) == 0 {
+		return nil
+	}
+	loaded, err := packages.Load(c.config(ctx), pkgs...)
+	if ctx.Err() != nil {
+		return stopped(ctx, pkgs)
+	}
+	if err != nil {
+		return &checkers.ToolError{Tool: "goanalysis", Err: fmt.Errorf("failed to load packages: %v", err)}
+	}
+	r := newReporter()
+	packages.Visit(loaded, nil, func(p *packages.Package) {
+		for _, e := range p.Errors {
+			r.add("", e.Pos, e.Msg)
+		}
+	})
+	// Analyzers cannot be interrupted, so they are left to complete in the
+	// background if ctx is done first.
+	type result struct {
+		graph *checker.Graph
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		graph, err := checker.Analyze(c.Analyzers, loaded, nil)
+		done <- result{graph, err}
+	}()
+	var graph *checker.Graph
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return &checkers.ToolError{Tool: "goanalysis", Err: res.err}
+		}
+		graph = res.graph
+	case <-ctx.Done():
+		return stopped(ctx, pkgs)
+	}
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			// Analyzers are not run on packages with errors, which are
+			// already reported.
+			if len(act.Package.Errors) == 0 {
+				r.add("", "", fmt.Sprintf("%s: %s: %v", act.Analyzer.Name, act.Package.PkgPath, act.Err))
+			}
+			continue
+		}
+		for _, d := range act.Diagnostics {
+			rule := d.Category
+			if rule == "" {
+				rule = act.Analyzer.Name
+			}
+			r.add(rule, act.Package.Fset.Position(d.Pos).String(), d.Message)
+		}
+	}
+	return checkers.Issues(r.issues()...)
+}
+
+// stopped returns an issue reporting that checking pkgs stopped because ctx is
+// done.
+func stopped(ctx context.Context, pkgs []string) error {
+	msg := fmt.Sprintf("goanalysis: stopped checking %s: %v", strings.Join(pkgs, " "), ctx.Err())
+	return checkers.Issues(checkers.Issue{Severity: checkers.SeverityError, Message: msg, Raw: msg})
+}
+
+func (c Check) config(ctx context.Context) *packages.Config {
+	mode := packages.LoadSyntax | packages.NeedModule
+	for _, a := range c.Analyzers {
+		if facts(a) {
+			// Analyzers using facts are also run on dependencies.
+			mode = packages.LoadAllSyntax | packages.NeedModule
+			break
+		}
+	}
+	cfg := &packages.Config{Context: ctx, Mode: mode, Tests: c.Tests}
+	if c.Tags != "" {
+		cfg.BuildFlags = []string{"-tags", c.Tags}
+	}
+	return cfg
+}
+
+// facts returns true if a or any analyzer it requires uses facts.
+func facts(a *analysis.Analyzer) bool {
+	if len(a.FactTypes) > 0 {
+		return true
+	}
+	for _, req := range a.Requires {
+		if facts(req) {
+			return true
+		}
+	}
+	return false
+}
+
+// reporter collects issues, removing duplicates reported for the test variants
+// of a package.
+type reporter struct {
+	wd   string
+	seen map[string]bool
+	list []checkers.Issue
+}
+
+func newReporter() *reporter {
+	wd, _ := os.Getwd()
+	return &reporter{wd: wd, seen: map[string]bool{}}
+}
+
+// add adds an issue for rule at pos, which may be empty if it is unknown.
+func (r *reporter) add(rule, pos, msg string) {
+	raw := msg
+	if pos != "" && pos != "-" {
+		raw = r.relative(pos) + ": " + msg
+	}
+	issue := checkers.ParseIssue(raw)
+	issue.RuleID = rule
+	key := rule + "\n" + raw
+	if !r.seen[key] {
+		r.seen[key] = true
+		r.list = append(r.list, issue)
+	}
+}
+
+// relative returns pos relative to the working directory if it is inside it.
+func (r *reporter) relative(pos string) string {
+	if r.wd == "" || !filepath.IsAbs(pos) {
+		return pos
+	}
+	if rel, err := filepath.Rel(r.wd, pos); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return pos
+}
+
+func (r *reporter) issues() []checkers.Issue {
+	sort.SliceStable(r.list, func(i, j int) bool {
+		a, b := r.list[i], r.list[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return r.list
+}
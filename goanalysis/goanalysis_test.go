@@ -0,0 +1,134 @@
+package goanalysis_test
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/printf"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+	"github.com/surullabs/lint/goanalysis"
+	"github.com/surullabs/lint/testutil"
+)
+
+// todo reports functions named TODO.
+var todo = &analysis.Analyzer{
+	Name: "todo",
+	Doc:  "reports functions named TODO",
+	Run: func(pass *analysis.Pass) (interface{}, error) {
+		for _, f := range pass.Files {
+			for _, decl := range f.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "TODO" {
+					pass.Reportf(fn.Pos(), "unfinished function")
+				}
+			}
+		}
+		return nil, nil
+	},
+}
+
+// issue returns a validator checking that err holds a single issue for rule
+// with a Raw suffix.
+func issue(rule, suffix string) func(err error) error {
+	return func(err error) error {
+		issues := lint.Issues(err)
+		if len(issues) != 1 || issues[0].RuleID != rule || !strings.HasSuffix(issues[0].Raw, suffix) {
+			return fmt.Errorf("expected a %s issue ending with %q, got %v", rule, suffix, issues)
+		}
+		return nil
+	}
+}
+
+func TestGoanalysis(t *testing.T) {
+	testutil.Test(t, "goanalysistest", []testutil.StaticCheckTest{
+		{
+			Checker: goanalysis.Check{Analyzers: []*analysis.Analyzer{todo, printf.Analyzer}},
+			Content: []byte(`package goanalysistest
+
+import "fmt"
+
+func Done() {
+	fmt.Printf("%d", 1)
+}
+`),
+			Validate: testutil.NoError,
+		},
+		{
+			Checker: goanalysis.Check{},
+			Content: []byte(`package goanalysistest
+
+func TODO() {}
+`),
+			Validate: testutil.NoError,
+		},
+		{
+			Checker: goanalysis.Check{Analyzers: []*analysis.Analyzer{todo, printf.Analyzer}},
+			Content: []byte(`package goanalysistest
+
+func TODO() {}
+`),
+			Validate: issue("todo", "file.go:3:1: unfinished function"),
+		},
+		{
+			// printf uses facts, so its dependencies are analyzed too.
+			Checker: goanalysis.Check{Analyzers: []*analysis.Analyzer{todo, printf.Analyzer}},
+			Content: []byte(`package goanalysistest
+
+import "fmt"
+
+func Done() {
+	fmt.Printf("%d", "one")
+}
+`),
+			Validate: issue("printf", "file.go:6:14: fmt.Printf format %d has arg \"one\" of wrong type string"),
+		},
+		{
+			Checker: goanalysis.Check{Analyzers: []*analysis.Analyzer{todo}},
+			Content: []byte(`package goanalysistest
+
+sfsff
+`),
+			Validate: testutil.Contains("file.go:3:1: expected declaration, found sfsff"),
+		},
+		{
+			Checker: goanalysis.Check{Analyzers: []*analysis.Analyzer{todo}, Tags: "tag"},
+			Content: []byte(`//go:build tag
+
+package goanalysistest
+
+func TODO() {}
+`),
+			Validate: issue("todo", "file.go:5:1: unfinished function"),
+		},
+	})
+}
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	block := &analysis.Analyzer{
+		Name: "block",
+		Doc:  "blocks until the test completes",
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			<-release
+			return nil, nil
+		},
+	}
+	testutil.Test(t, "goanalysistest", []testutil.StaticCheckTest{
+		{
+			Checker: lint.Timeout{Checker: goanalysis.Check{Analyzers: []*analysis.Analyzer{block}}, Duration: 200 * time.Millisecond},
+			Content: []byte("package goanalysistest\n"),
+			Validate: func(err error) error {
+				if _, ok := err.(*checkers.ToolError); ok {
+					return fmt.Errorf("expected an issue, got failure %v", err)
+				}
+				return issue("", "goanalysis: stopped checking goanalysistest: context deadline exceeded")(err)
+			},
+		},
+	})
+}
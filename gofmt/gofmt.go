@@ -1,40 +1,220 @@
+// Package gofmt checks that files are formatted as done by gofmt. Files are
+// formatted in-process, without running gofmt.
 package gofmt
 
 import (
 	"bytes"
 	"fmt"
-	"os/exec"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/surullabs/lint/checkers"
 )
 
 // Check is implements lint.Checker for gofmt.
 type Check struct {
+	// Simplify applies the simplifications of gofmt -s.
+	Simplify bool
+	// Rewrite is a rewrite rule of the form pattern -> replacement, as
+	// accepted by gofmt -r.
+	Rewrite string
 }
 
-// Check runs
-//   gofmt -d <files>
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "gofmt",
+		InformationURI: "https://golang.org/cmd/gofmt/",
+		Rules: []checkers.Rule{
+			{ID: "gofmt", Description: "Files not formatted with gofmt", HelpURI: "https://golang.org/cmd/gofmt/"},
+		},
+	})
+	checkers.RegisterChecker("gofmt", Check{})
+}
+
+// Check formats all files in pkgs, as done by
+//   gofmt -d [-s] [-r rule] <files>
+//
+// and reports each hunk of the difference as a separate issue, starting at the
+// first line that changed. Files that cannot be parsed are reported as well.
+func (c Check) Check(pkgs ...string) error {
+	var issues []checkers.Issue
+	err := c.each(pkgs, func(file string, src, res []byte) error {
+		issues = append(issues, hunks(file, src, res)...)
+		return nil
+	}, func(err error) {
+		issues = append(issues, parseErrors(err)...)
+	})
+	if err != nil {
+		return &checkers.ToolError{Tool: "gofmt", Err: err}
+	}
+	return checkers.Issues(issues...)
+}
+
+// Version returns the version of this module, which holds the simplify and
+// rewrite rules of gofmt. Formatting also depends on the Go version, which is
+// part of the key used to cache results.
+func (Check) Version() (string, error) {
+	return checkers.ModuleVersion("github.com/surullabs/lint"), nil
+}
+
+// Fix rewrites files in pkgs that are not formatted, as done by
+//   gofmt -w [-s] [-r rule] <files>
+func (c Check) Fix(pkgs ...string) error {
+	var errs []string
+	err := c.each(pkgs, func(file string, src, res []byte) error {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(file, res, info.Mode().Perm())
+	}, func(err error) {
+		errs = append(errs, err.Error())
+	})
+	if err != nil {
+		return err
+	}
+	return checkers.Error(errs...)
+}
+
+// Patch returns the differences reported by
+//   gofmt -d [-s] [-r rule] <files>
 //
 // for all files in pkgs.
-func (Check) Check(pkgs ...string) error {
-	var errs = []string{}
+func (c Check) Patch(pkgs ...string) (string, error) {
+	var patch []byte
+	var errs []string
+	err := c.each(pkgs, func(file string, src, res []byte) error {
+		name := filepath.ToSlash(file)
+		patch = append(patch, checkers.UnifiedDiff(name+".orig", src, name, res)...)
+		return nil
+	}, func(err error) {
+		errs = append(errs, err.Error())
+	})
+	if err == nil {
+		err = checkers.Error(errs...)
+	}
+	return string(patch), err
+}
 
+// each formats the files in pkgs and calls changed for each file that is not
+// formatted, with its source and the formatted result. Files that cannot be
+// parsed are passed to invalid. Errors returned by changed are returned
+// immediately.
+func (c Check) each(pkgs []string, changed func(file string, src, res []byte) error, invalid func(error)) error {
+	var rewrite func(*token.FileSet, *ast.File) *ast.File
+	if c.Rewrite != "" {
+		var err error
+		if rewrite, err = parseRewrite(c.Rewrite); err != nil {
+			return err
+		}
+	}
 	files, err := checkers.GoFiles(pkgs...)
 	if err != nil {
 		return err
 	}
-
-	for _, f := range files {
-		data, err := exec.Command("gofmt", "-d", f).CombinedOutput()
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
 		if err != nil {
-			return fmt.Errorf("%v: %s", err, string(data))
+			return err
 		}
-
-		str := bytes.TrimSpace(data)
-		if len(str) > 0 {
-			errs = append(errs, fmt.Sprintf("File not formatted: %s", string(str)))
+		res, err := c.format(file, src, rewrite)
+		if err != nil {
+			invalid(err)
+			continue
+		}
+		if !bytes.Equal(src, res) {
+			if err = changed(file, src, res); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
+}
 
-	return checkers.Error(errs...)
+// format returns src formatted as done by gofmt.
+func (c Check) format(file string, src []byte, rewrite func(*token.FileSet, *ast.File) *ast.File) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if rewrite != nil {
+		f = rewrite(fset, f)
+	}
+	ast.SortImports(fset, f)
+	if c.Simplify {
+		simplify(f)
+	}
+	var buf bytes.Buffer
+	if err = format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseErrors returns an issue for each error in err.
+func parseErrors(err error) []checkers.Issue {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return []checkers.Issue{checkers.ParseIssue(err.Error())}
+	}
+	issues := make([]checkers.Issue, len(list))
+	for i, e := range list {
+		issues[i] = checkers.ParseIssue(e.Error())
+	}
+	return issues
+}
+
+var hunkRE = regexp.MustCompile(`^@@ -([0-9]+)(?:,[0-9]+)? `)
+
+// hunks returns an issue for each hunk of the difference between src and res.
+// The line of each issue is the first line of src changed by the hunk, and the
+// message holds the hunk without its header, so that it does not change when
+// the hunk moves.
+func hunks(file string, src, res []byte) []checkers.Issue {
+	var issues []checkers.Issue
+	lines := strings.SplitAfter(string(checkers.UnifiedDiff("a", src, "b", res)), "\n")
+	for i := 0; i < len(lines); i++ {
+		m := hunkRE.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(m[1])
+		first := 0
+		var body []string
+		for i++; i < len(lines) && !strings.HasPrefix(lines[i], "@@ "); i++ {
+			if lines[i] == "" {
+				continue
+			}
+			if first == 0 && lines[i][0] != ' ' {
+				first = line
+			}
+			if first == 0 {
+				line++
+			}
+			body = append(body, lines[i])
+		}
+		i--
+		if first == 0 {
+			first = line
+		}
+		msg := "File not formatted:\n" + strings.TrimRight(strings.Join(body, ""), "\n")
+		issues = append(issues, checkers.Issue{
+			File:     file,
+			Line:     first,
+			Severity: checkers.SeverityError,
+			Message:  msg,
+			Raw:      fmt.Sprintf("%s:%d: %s", file, first, msg),
+		})
+	}
+	return issues
 }
@@ -1,8 +1,14 @@
 package gofmt_test
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
 	"github.com/surullabs/lint/gofmt"
 	"github.com/surullabs/lint/testutil"
 )
@@ -40,18 +46,120 @@ type A struct {
     Long string
 }
 `),
-			Validate: testutil.MatchesRegexp("^File not formatted.*gofmttest/file.go"),
+			Validate: testutil.MatchesRegexp("^[^\n]*gofmttest/file.go:8: File not formatted:\n \\)\n"),
 		},
 		{
 			Checker: gofmt.Check{},
 			Content: []byte(`package gofmttest
 
 		blah`),
-			Validate: testutil.Contains("expected declaration, found 'IDENT' blah"),
+			Validate: testutil.MatchesRegexp("file.go:3:3: expected declaration, found ('IDENT' )?blah"),
 		},
 	})
 }
 
+const hunks = `package gofmttest
+
+func A() {
+  return
+}
+
+func B() {}
+
+func C() {}
+
+func D() {}
+
+func E() {
+  return
+}
+`
+
+// lines returns a validator checking that err holds issues at lines.
+func lines(expected ...int) func(err error) error {
+	return func(err error) error {
+		issues := lint.Issues(err)
+		var found []int
+		for _, issue := range issues {
+			found = append(found, issue.Line)
+		}
+		if fmt.Sprint(found) != fmt.Sprint(expected) {
+			return fmt.Errorf("expected issues at %v, got %v", expected, issues)
+		}
+		return nil
+	}
+}
+
+func TestHunks(t *testing.T) {
+	testutil.Test(t, "gofmttest", []testutil.StaticCheckTest{
+		{
+			Checker:  gofmt.Check{},
+			Content:  []byte(hunks),
+			Validate: lines(4, 14),
+		},
+		{
+			Checker:  gofmt.Check{},
+			Content:  []byte("package gofmttest\n\nvar a = []struct{ A int }{struct{ A int }{1}}\n"),
+			Validate: testutil.NoError,
+		},
+		{
+			Checker:  gofmt.Check{Simplify: true},
+			Content:  []byte("package gofmttest\n\nvar a = []struct{ A int }{struct{ A int }{1}}\n"),
+			Validate: testutil.Contains("-var a = []struct{ A int }{struct{ A int }{1}}\n+var a = []struct{ A int }{{1}}"),
+		},
+		{
+			Checker:  gofmt.Check{Rewrite: "a[b:len(a)] -> a[b:]"},
+			Content:  []byte("package gofmttest\n\nvar a = []int{1}\nvar b = a[1:len(a)]\n"),
+			Validate: testutil.Contains("file.go:4: File not formatted:\n package gofmttest\n \n var a = []int{1}\n-var b = a[1:len(a)]\n+var b = a[1:]"),
+		},
+		{
+			Checker:  gofmt.Check{Rewrite: "a[b:len(a)]"},
+			Content:  []byte(hunks),
+			Validate: testutil.Contains("rewrite rule must be of the form 'pattern -> replacement'"),
+		},
+	})
+}
+
+const unformatted = `package gofmtfix
+
+func TestFunc() {
+  return
+}
+`
+
+func TestFix(t *testing.T) {
+	checkers.Unload("gofmtfix")
+	tmp, err := testutil.NewGOPATH("gofmtfix", []testutil.SourceFile{
+		{Content: []byte(unformatted), Dest: filepath.Join("gofmtfix", "file.go")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tmp.Reset()
+
+	g := lint.Group{gofmt.Check{}}
+	dir := filepath.Join(tmp.Path, "patches")
+	files, err := g.WritePatches(dir, "gofmtfix")
+	if err != nil || len(files) != 1 || files[0] != filepath.Join(dir, "gofmt.Check.patch") {
+		t.Fatal("unexpected patches", files, err)
+	}
+	patch, err := ioutil.ReadFile(files[0])
+	if err != nil || !strings.Contains(string(patch), "-  return\n+\treturn\n") {
+		t.Fatalf("unexpected patch %s: %v", patch, err)
+	}
+
+	if err = g.Fix("gofmtfix"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(tmp.Path, "src", "gofmtfix", "file.go"))
+	if err != nil || string(data) != strings.Replace(unformatted, "  ", "\t", 1) {
+		t.Fatalf("file not fixed %s: %v", data, err)
+	}
+	if files, err = g.WritePatches(dir, "gofmtfix"); err != nil || len(files) != 0 {
+		t.Fatal("unexpected patches after fix", files, err)
+	}
+}
+
 const expectedUnformatted = `File not formatted: diff GOFMT_TMP_FOLDER
 --- GOFMT_TMP_FOLDER
 +++ GOFMT_TMP_FOLDER
@@ -0,0 +1,349 @@
+// Package goimports checks that imports are as goimports would leave them, and
+// that they are grouped into standard library, third party and local imports.
+// goimports is run in-process.
+package goimports
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/imports"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+// Check implements lint.Checker for goimports.
+//
+// Each file is reported for imports that goimports -local Local would add or
+// remove, and for import declarations that it would format differently. Imports
+// must also be grouped, in order, into standard library, third party and local
+// packages, with each group separated by a blank line. Local packages are those
+// with a prefix in Local.
+type Check struct {
+	// Local is a comma separated list of import path prefixes of local
+	// packages, as passed to goimports -local.
+	Local string
+}
+
+// Rule IDs of the issues reported by Check.
+const (
+	RuleMissing = "missing-import"
+	RuleUnused  = "unused-import"
+	RuleFormat  = "import-format"
+	RuleGroups  = "import-groups"
+)
+
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "goimports",
+		InformationURI: "https://pkg.go.dev/golang.org/x/tools/cmd/goimports",
+		Rules: []checkers.Rule{
+			{ID: RuleMissing, Description: "Imports that goimports would add"},
+			{ID: RuleUnused, Description: "Imports that goimports would remove"},
+			{ID: RuleFormat, Description: "Imports that goimports would format differently"},
+			{ID: RuleGroups, Description: "Imports not grouped into standard library, third party and local imports"},
+		},
+	})
+	checkers.RegisterChecker("goimports", Check{})
+}
+
+// localPrefix guards imports.LocalPrefix.
+var localPrefix sync.Mutex
+
+// Check reports imports in pkgs that are not as goimports would leave them.
+func (c Check) Check(pkgs ...string) error {
+	files, err := checkers.GoFiles(pkgs...)
+	if err != nil {
+		return &checkers.ToolError{Tool: "goimports", Err: err}
+	}
+	var issues []checkers.Issue
+	for _, file := range files {
+		found, err := c.check(file)
+		if err != nil {
+			return &checkers.ToolError{Tool: "goimports", Err: err}
+		}
+		issues = append(issues, found...)
+	}
+	return checkers.Issues(issues...)
+}
+
+// Version returns the versions of this module and of golang.org/x/tools, which
+// holds goimports. It is part of the key used to cache results.
+func (Check) Version() (string, error) {
+	return checkers.ModuleVersion("github.com/surullabs/lint") + " " + checkers.ModuleVersion("golang.org/x/tools"), nil
+}
+
+// Fix rewrites files in pkgs whose imports are not as goimports would leave
+// them, as done by
+//   goimports -w [-local Local] <files>
+//
+// goimports does not move imports between existing groups, so Check may still
+// report grouping issues after Fix.
+func (c Check) Fix(pkgs ...string) error {
+	var errs []string
+	err := c.each(pkgs, func(file string, src, res []byte) error {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(file, res, info.Mode().Perm())
+	}, func(err error) {
+		errs = append(errs, err.Error())
+	})
+	if err != nil {
+		return err
+	}
+	return checkers.Error(errs...)
+}
+
+// Patch returns the differences reported by
+//   goimports -d [-local Local] <files>
+//
+// for all files in pkgs.
+func (c Check) Patch(pkgs ...string) (string, error) {
+	var patch []byte
+	var errs []string
+	err := c.each(pkgs, func(file string, src, res []byte) error {
+		name := filepath.ToSlash(file)
+		patch = append(patch, checkers.UnifiedDiff(name+".orig", src, name, res)...)
+		return nil
+	}, func(err error) {
+		errs = append(errs, err.Error())
+	})
+	if err == nil {
+		err = checkers.Error(errs...)
+	}
+	return string(patch), err
+}
+
+// each runs goimports on the files in pkgs and calls changed for each file it
+// would change, with its source and the result. Errors from goimports are
+// passed to invalid, while files that cannot be parsed are skipped as in Check.
+// Errors returned by changed are returned immediately.
+func (c Check) each(pkgs []string, changed func(file string, src, res []byte) error, invalid func(error)) error {
+	files, err := checkers.GoFiles(pkgs...)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if _, err = parser.ParseFile(token.NewFileSet(), file, src, parser.ImportsOnly); err != nil {
+			continue
+		}
+		res, err := c.process(file, src, false)
+		if err != nil {
+			invalid(err)
+			continue
+		}
+		if !bytes.Equal(src, res) {
+			if err = changed(file, src, res); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c Check) check(file string) ([]checkers.Issue, error) {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		// Parse errors are reported by gofmt and compilers.
+		return nil, nil
+	}
+	fixed, err := c.process(file, src, false)
+	if err != nil {
+		return []checkers.Issue{checkers.ParseIssue(err.Error())}, nil
+	}
+	r := &reporter{file: file, fset: fset}
+	r.imports(f, fixed)
+	if formatted, err := c.process(file, src, true); err == nil {
+		r.format(f, src, formatted)
+	}
+	r.groups(f, c.Local)
+	return r.issues, nil
+}
+
+// process returns src as written by goimports. Only imports are sorted and
+// grouped if formatOnly is true.
+func (c Check) process(file string, src []byte, formatOnly bool) ([]byte, error) {
+	localPrefix.Lock()
+	defer localPrefix.Unlock()
+	imports.LocalPrefix = c.Local
+	return imports.Process(file, src, &imports.Options{
+		Comments:   true,
+		TabIndent:  true,
+		TabWidth:   8,
+		FormatOnly: formatOnly,
+	})
+}
+
+type reporter struct {
+	file   string
+	fset   *token.FileSet
+	issues []checkers.Issue
+}
+
+func (r *reporter) report(pos token.Pos, rule, msg string) {
+	line := r.fset.Position(pos).Line
+	r.issues = append(r.issues, checkers.Issue{
+		File:     r.file,
+		Line:     line,
+		Severity: checkers.SeverityError,
+		RuleID:   rule,
+		Message:  msg,
+		Raw:      fmt.Sprintf("%s:%d: %s", r.file, line, msg),
+	})
+}
+
+// imports reports imports of f that are missing or unused, compared to fixed.
+func (r *reporter) imports(f *ast.File, fixed []byte) {
+	ff, err := parser.ParseFile(token.NewFileSet(), r.file, fixed, parser.ImportsOnly)
+	if err != nil {
+		return
+	}
+	want := map[string]bool{}
+	for _, spec := range ff.Imports {
+		want[importKey(spec)] = true
+	}
+	have := map[string]bool{}
+	for _, spec := range f.Imports {
+		have[importKey(spec)] = true
+		if !want[importKey(spec)] {
+			r.report(spec.Pos(), RuleUnused, fmt.Sprintf("unused import %s", importKey(spec)))
+		}
+	}
+	pos := f.Name.Pos()
+	if len(f.Imports) > 0 {
+		pos = f.Imports[0].Pos()
+	}
+	for _, spec := range ff.Imports {
+		if !have[importKey(spec)] {
+			r.report(pos, RuleMissing, fmt.Sprintf("missing import %s", importKey(spec)))
+		}
+	}
+}
+
+// format reports the import declarations of f if they differ in formatted.
+func (r *reporter) format(f *ast.File, src, formatted []byte) {
+	ff, err := parser.ParseFile(token.NewFileSet(), r.file, formatted, parser.ImportsOnly)
+	if err != nil || len(f.Imports) == 0 {
+		return
+	}
+	have, want := importDecls(r.fset, f, src), importDecls(nil, ff, formatted)
+	if have != want {
+		r.report(f.Imports[0].Pos(), RuleFormat, "imports are not formatted as goimports would:\n"+want)
+	}
+}
+
+// importDecls returns the text of the import declarations of f, which was
+// parsed from src.
+func importDecls(fset *token.FileSet, f *ast.File, src []byte) string {
+	var decls []*ast.GenDecl
+	for _, d := range f.Decls {
+		if g, ok := d.(*ast.GenDecl); ok && g.Tok == token.IMPORT {
+			decls = append(decls, g)
+		}
+	}
+	if len(decls) == 0 {
+		return ""
+	}
+	// The offsets of a file are relative to its base, which is 1 for the
+	// first file in a FileSet.
+	base := 1
+	if fset != nil {
+		base = fset.File(f.Pos()).Base()
+	}
+	return string(src[int(decls[0].Pos())-base : int(decls[len(decls)-1].End())-base])
+}
+
+func importKey(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name + " " + spec.Path.Value
+	}
+	return spec.Path.Value
+}
+
+// Import classes, in the order their groups must appear.
+const (
+	standard = iota
+	thirdParty
+	local
+)
+
+var classNames = []string{"standard library", "third party", "local"}
+
+func class(path, localPrefixes string) int {
+	for _, p := range strings.Split(localPrefixes, ",") {
+		if p = strings.TrimSpace(p); p != "" && strings.HasPrefix(path, p) {
+			return local
+		}
+	}
+	if !strings.Contains(strings.Split(path, "/")[0], ".") {
+		return standard
+	}
+	return thirdParty
+}
+
+// groups reports imports that are not grouped by class in the order standard
+// library, third party and local. Groups are separated by blank lines or are in
+// separate declarations.
+func (r *reporter) groups(f *ast.File, localPrefixes string) {
+	done := map[int]bool{}
+	current, prevEnd := -1, 0
+	var prevDecl *ast.GenDecl
+	for _, d := range f.Decls {
+		g, ok := d.(*ast.GenDecl)
+		if !ok || g.Tok != token.IMPORT {
+			continue
+		}
+		for _, s := range g.Specs {
+			spec := s.(*ast.ImportSpec)
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || path == "C" {
+				continue
+			}
+			start := spec.Pos()
+			if spec.Doc != nil {
+				start = spec.Doc.Pos()
+			}
+			newGroup := g != prevDecl || r.fset.Position(start).Line > prevEnd+1
+			prevDecl, prevEnd = g, r.fset.Position(spec.End()).Line
+			c := class(path, localPrefixes)
+			if !newGroup {
+				if c != current {
+					r.report(spec.Pos(), RuleGroups, fmt.Sprintf("%s import %s must be in a separate group from %s imports",
+						classNames[c], path, classNames[current]))
+				}
+				continue
+			}
+			if current >= 0 {
+				done[current] = true
+			}
+			if done[c] {
+				r.report(spec.Pos(), RuleGroups, fmt.Sprintf("%s import %s must be in the same group as other %s imports",
+					classNames[c], path, classNames[c]))
+			} else if c < current {
+				r.report(spec.Pos(), RuleGroups, fmt.Sprintf("%s import %s must be in a group before %s imports",
+					classNames[c], path, classNames[current]))
+			}
+			current = c
+		}
+	}
+}
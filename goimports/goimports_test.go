@@ -0,0 +1,155 @@
+package goimports_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+	"github.com/surullabs/lint/goimports"
+	"github.com/surullabs/lint/testutil"
+)
+
+func TestGoimports(t *testing.T) {
+	for i, test := range []struct {
+		src      string
+		expected []string
+	}{
+		{
+			`package app
+
+import (
+	"fmt"
+
+	"example.com/lib"
+
+	"example.com/app/util"
+)
+
+var _ = fmt.Sprint(lib.X, util.X)
+`,
+			nil,
+		},
+		{
+			`package app
+
+import (
+	"fmt"
+	"os"
+)
+
+var _ = fmt.Sprint(strings.ToUpper("a"))
+`,
+			[]string{
+				`unused-import 5: unused import "os"`,
+				`missing-import 4: missing import "strings"`,
+			},
+		},
+		{
+			`package app
+
+import (
+	"fmt"
+	"example.com/lib"
+)
+
+var _ = fmt.Sprint(lib.X)
+`,
+			[]string{
+				"import-format 4: imports are not formatted as goimports would:\nimport (\n\t\"fmt\"\n\n\t\"example.com/lib\"\n)",
+				"import-groups 5: third party import example.com/lib must be in a separate group from standard library imports",
+			},
+		},
+		{
+			`package app
+
+import (
+	"example.com/app/util"
+
+	"example.com/lib"
+)
+
+import "fmt"
+
+var _ = fmt.Sprint(lib.X, util.X)
+`,
+			[]string{
+				"import-format 4: imports are not formatted as goimports would:\nimport (\n\t\"example.com/app/util\"\n\n\t\"fmt\"\n\n\t\"example.com/lib\"\n)",
+				"import-groups 6: third party import example.com/lib must be in a group before local imports",
+				"import-groups 9: standard library import fmt must be in a group before third party imports",
+			},
+		},
+		{
+			`package app
+
+import (
+	"fmt"
+
+	"os"
+)
+
+var _ = fmt.Sprint(os.Args)
+`,
+			[]string{"import-groups 6: standard library import os must be in the same group as other standard library imports"},
+		},
+	} {
+		var found []string
+		for _, issue := range lint.Issues(check(t, test.src)) {
+			found = append(found, fmt.Sprintf("%s %d: %s", issue.RuleID, issue.Line, issue.Message))
+		}
+		if strings.Join(found, "\n") != strings.Join(test.expected, "\n") {
+			t.Errorf("%d: expected\n%s\ngot\n%s", i, strings.Join(test.expected, "\n"), strings.Join(found, "\n"))
+		}
+	}
+}
+
+func check(t *testing.T, src string) error {
+	checkers.Unload("example.com/app")
+	tmp, err := testutil.NewGOPATH("goimportstest", []testutil.SourceFile{
+		{Content: []byte(src), Dest: filepath.Join("example.com", "app", "app.go")},
+		{Content: []byte("package lib\n\nvar X = 1\n"), Dest: filepath.Join("example.com", "lib", "lib.go")},
+		{Content: []byte("package util\n\nvar X = 1\n"), Dest: filepath.Join("example.com", "app", "util", "util.go")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tmp.Reset()
+	return goimports.Check{Local: "example.com/app"}.Check("example.com/app")
+}
+
+func TestFix(t *testing.T) {
+	checkers.Unload("example.com/app")
+	src := "package app\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nvar _ = fmt.Sprint(strings.ToUpper(\"a\"))\n"
+	tmp, err := testutil.NewGOPATH("goimportsfix", []testutil.SourceFile{
+		{Content: []byte(src), Dest: filepath.Join("example.com", "app", "app.go")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tmp.Reset()
+
+	g := lint.Group{goimports.Check{Local: "example.com/app"}}
+	dir := filepath.Join(tmp.Path, "patches")
+	files, err := g.WritePatches(dir, "example.com/app")
+	if err != nil || len(files) != 1 || files[0] != filepath.Join(dir, "goimports.Check.patch") {
+		t.Fatal("unexpected patches", files, err)
+	}
+	patch, err := ioutil.ReadFile(files[0])
+	if err != nil || !strings.Contains(string(patch), "-\t\"os\"\n+\t\"strings\"\n") {
+		t.Fatalf("unexpected patch %s: %v", patch, err)
+	}
+
+	if err = g.Fix("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(tmp.Path, "src", "example.com", "app", "app.go"))
+	if err != nil || string(data) != strings.Replace(src, "\"os\"", "\"strings\"", 1) {
+		t.Fatalf("file not fixed %s: %v", data, err)
+	}
+	if files, err = g.WritePatches(dir, "example.com/app"); err != nil || len(files) != 0 {
+		t.Fatal("unexpected patches after fix", files, err)
+	}
+}
@@ -1,12 +1,162 @@
 package golint
 
-import "github.com/surullabs/lint/checkers"
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
-// Check implements a golint Checker
+	"github.com/surullabs/lint/checkers"
+)
+
+// Check implements a golint Checker.
+//
+// Each issue is classified into a rule, such as exported-doc or initialism,
+// which is reported as its RuleID. Rules lists the rules and the messages they
+// match. Messages that match no rule are reported without a RuleID.
 type Check struct {
+	// MinConfidence is the minimum confidence of reported issues, as passed
+	// to golint -min_confidence. golint's default of 0.8 is used if it is 0.
+	MinConfidence float64
+	// Disable lists IDs of rules that are not reported.
+	Disable []string
+}
+
+// Rule is a category of golint messages.
+type Rule struct {
+	// ID is reported as the RuleID of issues in the category.
+	ID string
+	// Description is a short description of the rule.
+	Description string
+	// Match matches messages in the category.
+	Match *regexp.Regexp
+}
+
+// Rules lists the rules issues are classified into. The first rule that
+// matches a message is used.
+var Rules = []Rule{
+	{"package-comment", "Package comments", regexp.MustCompile(`^(should have a package comment|package comment )`)},
+	{"exported-doc", "Comments on exported identifiers", regexp.MustCompile(`^(exported \S+ \S+ should have comment|comment on exported )`)},
+	{"exported-declaration", "Exported identifiers sharing a declaration", regexp.MustCompile(`^exported \S+ \S+ should have its own declaration`)},
+	{"unexported-return", "Exported functions returning unexported types", regexp.MustCompile(`^exported \S+ \S+ returns unexported type `)},
+	{"blank-import", "Blank imports without comments", regexp.MustCompile(`^a blank import should be only`)},
+	{"dot-import", "Dot imports", regexp.MustCompile(`^should not use dot imports`)},
+	{"package-name", "Package names", regexp.MustCompile(`^don't use (MixedCaps|an underscore) in package name`)},
+	{"all-caps", "Names using ALL_CAPS", regexp.MustCompile(`^don't use ALL_CAPS in Go names`)},
+	{"leading-k", "Names with a leading k", regexp.MustCompile(`^don't use leading k in Go names`)},
+	{"underscore", "Names using underscores", regexp.MustCompile(`^don't use underscores in Go names`)},
+	{"initialism", "Names with incorrectly cased initialisms", regexp.MustCompile(`^[\w ]+ \w+ should be \w+$`)},
+	{"stutter", "Names that repeat the package name", regexp.MustCompile(`by other packages, and that stutters`)},
+	{"error-name", "Names of error variables", regexp.MustCompile(`^error var \S+ should have name of the form`)},
+	{"receiver-name", "Receiver names", regexp.MustCompile(`^receiver name `)},
+	{"time-suffix", "Unit suffixes on time.Duration names", regexp.MustCompile(`don't use unit-specific suffix`)},
+	{"context-arg", "Position of context.Context parameters", regexp.MustCompile(`^context\.Context should be the first parameter`)},
+	{"context-key", "Basic types used as context keys", regexp.MustCompile(`^should not use basic type \S+ as key in context\.WithValue`)},
+	{"error-return", "Position of returned errors", regexp.MustCompile(`^error should be the last type`)},
+	{"error-string", "Capitalized or punctuated error strings", regexp.MustCompile(`^error strings should not be capitalized`)},
+	{"errorf", "errors.New(fmt.Sprintf(...)) instead of fmt.Errorf", regexp.MustCompile(`^should replace .*\(fmt\.Sprintf\(\.\.\.\)\) with `)},
+	{"increment-decrement", "+= 1 and -= 1 instead of ++ and --", regexp.MustCompile(`^should replace .* [+-]= 1 with `)},
+	{"range-loop", "Unused range values", regexp.MustCompile(`^should omit (2nd value|values) from range`)},
+	{"indent-error-flow", "Else blocks after a return", regexp.MustCompile(`^if block ends with a return statement`)},
+}
+
+// Binary is the pinned version of golint that is installed and run.
+var Binary = checkers.Binary{
+	Name:    "golint",
+	Package: "golang.org/x/lint/golint",
+	Module:  "golang.org/x/lint",
+	Version: "v0.0.0-20241112194109-818c5a804067",
+}
+
+func init() {
+	rules := []checkers.Rule{
+		{ID: "golint", Description: "Style mistakes", HelpURI: "https://github.com/golang/lint"},
+	}
+	for _, r := range Rules {
+		rules = append(rules, checkers.Rule{ID: r.ID, Description: r.Description, HelpURI: "https://github.com/golang/lint"})
+	}
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "golint",
+		InformationURI: "https://github.com/golang/lint",
+		Rules:          rules,
+	})
+	checkers.RegisterChecker("golint", Check{})
 }
 
 // Check implements lint.Checker for golint.
-func (Check) Check(pkgs ...string) error {
-	return checkers.Lint("golint", "", "github.com/golang/lint/golint", pkgs)
+func (c Check) Check(pkgs ...string) error {
+	return c.CheckContext(context.Background(), pkgs...)
+}
+
+// CheckContext implements lint.ContextChecker for golint.
+func (c Check) CheckContext(ctx context.Context, pkgs ...string) error {
+	disabled := map[string]bool{}
+	for _, id := range c.Disable {
+		if find(id) == nil {
+			return &checkers.ToolError{Tool: "golint", Err: fmt.Errorf("unknown rule %q, must be one of %s", id, ids())}
+		}
+		disabled[id] = true
+	}
+	err := checkers.LintBinary(ctx, Binary, pkgs, c.Args()...)
+	errs, ok := err.(interface {
+		Errors() []string
+	})
+	if !ok {
+		return err
+	}
+	var issues []checkers.Issue
+	for _, str := range errs.Errors() {
+		issue := checkers.ParseIssue(str)
+		issue.RuleID = RuleID(issue.Message)
+		if !disabled[issue.RuleID] {
+			issues = append(issues, issue)
+		}
+	}
+	return checkers.Issues(issues...)
 }
+
+// RuleID returns the ID of the first rule in Rules that matches message, or an
+// empty string if none do.
+func RuleID(message string) string {
+	for _, r := range Rules {
+		if r.Match.MatchString(message) {
+			return r.ID
+		}
+	}
+	return ""
+}
+
+func find(id string) *Rule {
+	for i := range Rules {
+		if Rules[i].ID == id {
+			return &Rules[i]
+		}
+	}
+	return nil
+}
+
+func ids() string {
+	var res []string
+	for _, r := range Rules {
+		res = append(res, r.ID)
+	}
+	sort.Strings(res)
+	return strings.Join(res, ", ")
+}
+
+// Args returns command line arguments used for golint
+func (c Check) Args() []string {
+	if c.MinConfidence == 0 {
+		return nil
+	}
+	return []string{"-min_confidence", strconv.FormatFloat(c.MinConfidence, 'g', -1, 64)}
+}
+
+// Version returns the pinned version of golint. It is part of the key used to
+// cache results.
+func (Check) Version() (string, error) { return Binary.String(), nil }
+
+// Binaries returns the pinned version of golint. It is used by lint.Prefetch.
+func (Check) Binaries() []checkers.Binary { return []checkers.Binary{Binary} }
@@ -65,6 +65,82 @@ func TestFunc() {
 			Validate: testutil.SkippedErrors(
 				`exported function TestFunc should have comment or be unexported`),
 		},
+		{
+			Checker: golint.Check{Disable: []string{"exported-doc"}},
+			Content: []byte(`package golinttest
+
+func TestFunc() {
+	i := 0
+	i += 1
+}
+`),
+			Validate: testutil.HasSuffix("file.go:5:2: should replace i += 1 with i++"),
+		},
+		{
+			Checker: golint.Check{MinConfidence: 0.1},
+			Content: []byte(`package golinttest
+`),
+			Validate: testutil.HasSuffix("should have a package comment, unless it's in another file for this package"),
+		},
+		{
+			Checker:  golint.Check{Disable: []string{"unknown"}},
+			Content:  []byte(`package golinttest`),
+			Validate: testutil.Contains(`unknown rule "unknown", must be one of all-caps, blank-import,`),
+		},
 	},
 	)
 }
+
+func TestRuleID(t *testing.T) {
+	for _, test := range []struct {
+		message, id string
+	}{
+		{`package comment should be of the form "Package foo ..."`, "package-comment"},
+		{"package comment should not have leading space", "package-comment"},
+		{"should have a package comment, unless it's in another file for this package", "package-comment"},
+		{"exported type T should have comment or be unexported", "exported-doc"},
+		{"exported method T.A should have comment or be unexported", "exported-doc"},
+		{"exported const A should have comment (or a comment on this block) or be unexported", "exported-doc"},
+		{`comment on exported function K should be of the form "K ..."`, "exported-doc"},
+		{"exported var B should have its own declaration", "exported-declaration"},
+		{"exported func J returns unexported type foo.t2, which can be annoying to use", "unexported-return"},
+		{"a blank import should be only in a main or test package, or have a comment justifying it", "blank-import"},
+		{"should not use dot imports", "dot-import"},
+		{"don't use an underscore in package name", "package-name"},
+		{"don't use MixedCaps in package name; myPkg should be mypkg", "package-name"},
+		{"don't use ALL_CAPS in Go names; use CamelCase", "all-caps"},
+		{"don't use leading k in Go names; const kFoo should be foo", "leading-k"},
+		{"don't use underscores in Go names; var foo_bar should be fooBar", "underscore"},
+		{"var userId should be userID", "initialism"},
+		{"func parameter userId should be userID", "initialism"},
+		{"type name will be used as foo.FooBar by other packages, and that stutters; consider calling this Bar", "stutter"},
+		{"error var fooErr should have name of the form errFoo", "error-name"},
+		{`receiver name should be a reflection of its identity; don't use generic names such as "this" or "self"`, "receiver-name"},
+		{"receiver name should not be an underscore, omit the name if it is unused", "receiver-name"},
+		{"receiver name a should be consistent with previous receiver name b for T", "receiver-name"},
+		{`var delayMs is of type time.Duration; don't use unit-specific suffix "Ms"`, "time-suffix"},
+		{"context.Context should be the first parameter of a function", "context-arg"},
+		{"should not use basic type string as key in context.WithValue", "context-key"},
+		{"error should be the last type when returning multiple items", "error-return"},
+		{"error strings should not be capitalized or end with punctuation or a newline", "error-string"},
+		{"should replace errors.New(fmt.Sprintf(...)) with fmt.Errorf(...)", "errorf"},
+		{"should replace i += 1 with i++", "increment-decrement"},
+		{"should replace i -= 1 with i--", "increment-decrement"},
+		{"should omit 2nd value from range; this loop is equivalent to `for k := range ...`", "range-loop"},
+		{"should omit values from range; this loop is equivalent to `for range ...`", "range-loop"},
+		{"if block ends with a return statement, so drop this else and outdent its block", "indent-error-flow"},
+		{"expected declaration, found sfsff", ""},
+	} {
+		if id := golint.RuleID(test.message); id != test.id {
+			t.Errorf("%s: expected %q, got %q", test.message, test.id, id)
+		}
+	}
+}
+
+func TestArgs(t *testing.T) {
+	testutil.TestArgs(t, []testutil.ArgTest{
+		{A: golint.Check{}, Expected: nil},
+		{A: golint.Check{MinConfidence: 0.5}, Expected: []string{"-min_confidence", "0.5"}},
+		{A: golint.Check{MinConfidence: 0.5, Disable: []string{"stutter"}}, Expected: []string{"-min_confidence", "0.5"}},
+	})
+}
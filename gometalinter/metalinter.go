@@ -2,6 +2,7 @@ package gometalinter
 
 import (
 	"fmt"
+	"go/build"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -19,6 +20,17 @@ type Check struct {
 	Args []string
 }
 
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "gometalinter",
+		InformationURI: "https://github.com/alecthomas/gometalinter",
+		Rules: []checkers.Rule{
+			{ID: "gometalinter", Description: "Issues reported by gometalinter", HelpURI: "https://github.com/alecthomas/gometalinter"},
+		},
+	})
+	checkers.RegisterChecker("gometalinter", Check{})
+}
+
 // Check runs a vendored version of gometalinter. It builds the
 // metalinter by detecting the location of the vendor directory and
 // using that as the GOPATH for building the metalinter binary. This is
@@ -58,55 +70,49 @@ func runMetalinter(args ...string) error {
 }
 
 func installMetaLinter() ([]string, string, error) {
-	root := ""
 	// Look up the actual package path of the lint install instead of assuming it is
 	// github.com/surullabs/lint. This is needed to handle cases where this library
 	// is itself vendored.
-	path := filepath.Dir(reflect.TypeOf(Check{}).PkgPath())
-	gopath := os.Getenv("GOPATH")
-	if gopath == "" {
-		return nil, "", fmt.Errorf("%s: GOPATH not set when looking for source location", path)
-	}
-	gopaths := strings.Split(gopath, string(os.PathListSeparator))
-	for _, p := range gopaths {
-		lintPath := filepath.Join(p, "src", path)
-		if _, err := os.Stat(lintPath); err == nil {
-			root = filepath.Join(lintPath, "gometalinter", "_vendored")
-			break
-		}
+	path := reflect.TypeOf(Check{}).PkgPath()
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find cwd: %v", err)
 	}
-	if root == "" {
-		return nil, "", fmt.Errorf("%s: not found under GOPATH (%v)", path, gopath)
+	// build.Import uses go list to find the package when in module mode and
+	// searches the GOPATH otherwise.
+	pkg, err := build.Import(path, wd, build.FindOnly)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: source location not found: %v", path, err)
 	}
+	root := filepath.Join(pkg.Dir, "_vendored")
 
 	// Check to see if the bin exists
 	bin := filepath.Join(root, "bin", "gometalinter")
 	// Always rebuild the binary. This might seem wasteful, but if the vendored version
 	// is updated we need to have the latest version of the binary rebuilt.
 	env := os.Environ()
-	gi, orig := -1, ""
 	for i := range env {
 		// Messing with the local process environment can have undesirable effects, so
-		// create a copy and replace GOPATH
-		if strings.HasPrefix(env[i], "GOPATH=") {
-			env[i], gi, orig = fmt.Sprintf("GOPATH=%v", root), i, env[i]
-		} else if strings.HasPrefix(env[i], "PATH=") {
-			env[i] = fmt.Sprintf("PATH=%s%c%s", filepath.Join(root, "bin"), filepath.ListSeparator, env[i])
+		// create a copy.
+		if strings.HasPrefix(env[i], "PATH=") {
+			env[i] = fmt.Sprintf("PATH=%s%c%s", filepath.Join(root, "bin"), filepath.ListSeparator, env[i][len("PATH="):])
 		}
 	}
+	// The vendored sources use the GOPATH layout. Later entries take precedence
+	// when running a command, so these replace any existing values.
+	installEnv := append(append([]string{}, env...), "GOPATH="+root, "GO111MODULE=off")
 	cmd := exec.Command("go", "install", "github.com/alecthomas/gometalinter")
-	cmd.Env = env
+	cmd.Env = installEnv
 	if out, err := cmd.CombinedOutput(); err != nil {
-		return nil, "", fmt.Errorf("failed to install gometalinter: %v\n%s", err, string(out))
+		return nil, "", &checkers.InstallError{Bin: "gometalinter", Err: fmt.Errorf("failed to install gometalinter: %v\n%s", err, string(out))}
 	}
 	if _, err := os.Stat(bin); err != nil {
-		return nil, "", fmt.Errorf("gometalinter not installed at %v: %v", bin, err)
+		return nil, "", &checkers.InstallError{Bin: "gometalinter", Err: fmt.Errorf("gometalinter not installed at %v: %v", bin, err)}
 	}
 	cmd = exec.Command(bin, "--install")
-	cmd.Env = env
+	cmd.Env = installEnv
 	if out, err := cmd.CombinedOutput(); err != nil {
-		return nil, "", fmt.Errorf("failed to install vendored linters: %v\n%s", err, string(out))
+		return nil, "", &checkers.InstallError{Bin: "gometalinter", Err: fmt.Errorf("failed to install vendored linters: %v\n%s", err, string(out))}
 	}
-	env[gi] = orig
 	return env, bin, nil
 }
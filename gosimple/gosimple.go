@@ -1,21 +1,48 @@
 package gosimple
 
 import (
+	"context"
+
 	"github.com/surullabs/lint/checkers"
-	_ "honnef.co/go/tools/simple" // Ensure the gosimple bin is downloaded.
+	"github.com/surullabs/lint/gostaticcheck"
 )
 
 // Check implements a gosimple Checker (https://github.com/dominikh/go-simple)
+//
+// gosimple is no longer released on its own. Its checks are run using the
+// version of staticcheck pinned by gostaticcheck.Binary, limited to the
+// simplification checks.
 type Check struct {
 	// Tags is a list of space separated build tags
 	Tags string
 }
 
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "gosimple",
+		InformationURI: "https://github.com/dominikh/go-simple",
+		RuleURI:        "https://staticcheck.io/docs/checks#%s",
+		Rules: []checkers.Rule{
+			{ID: "gosimple", Description: "Code simplifications", HelpURI: "https://github.com/dominikh/go-simple"},
+		},
+	})
+	checkers.RegisterChecker("gosimple", Check{})
+}
+
 // Check runs gosimple for pkg
 func (c Check) Check(pkgs ...string) error {
-	return checkers.Lint("gosimple", "", "honnef.co/go/tools/cmd/gosimple", pkgs, c.Args()...)
+	return c.CheckContext(context.Background(), pkgs...)
 }
 
+// CheckContext runs gosimple for pkgs, stopping if ctx is done.
+func (c Check) CheckContext(ctx context.Context, pkgs ...string) error {
+	return checkers.LintBinary(ctx, gostaticcheck.Binary, pkgs, append([]string{"-checks", "S1*"}, c.Args()...)...)
+}
+
+// Version returns the pinned version of staticcheck. It is part of the key used
+// to cache results.
+func (Check) Version() (string, error) { return gostaticcheck.Binary.String(), nil }
+
 // Args returns command line arguments used for gosimple
 func (c Check) Args() []string {
 	var args []string
@@ -24,3 +51,6 @@ func (c Check) Args() []string {
 	}
 	return args
 }
+
+// Binaries returns the pinned version of staticcheck. It is used by lint.Prefetch.
+func (Check) Binaries() []checkers.Binary { return []checkers.Binary{gostaticcheck.Binary} }
@@ -1,21 +1,59 @@
 package gostaticcheck
 
 import (
+	"context"
+
 	"github.com/surullabs/lint/checkers"
-	_ "honnef.co/go/tools/staticcheck" // Ensure the staticcheck bin is downloaded.
 )
 
 // Check implements a gostaticcheck Checker (https://github.com/dominikh/go-staticcheck)
+//
+// staticcheck also runs the simplification checks reported by gosimple and the
+// style checks overlapping with golint. These are disabled, so that only the
+// default SA checks are reported.
 type Check struct {
 	// Tags is a list of space separated build tags
 	Tags string
 }
 
+// Binary is the pinned version of staticcheck that is installed and run.
+var Binary = checkers.Binary{
+	Name:    "staticcheck",
+	Package: "honnef.co/go/tools/cmd/staticcheck",
+	Module:  "honnef.co/go/tools",
+	Version: "v0.7.0",
+}
+
+// checks selects the staticcheck checks that are run, leaving out the S1 checks
+// run by gosimple and the ST1 style checks.
+const checks = "inherit,-S1*,-ST1*"
+
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "staticcheck",
+		InformationURI: "https://github.com/dominikh/go-staticcheck",
+		RuleURI:        "https://staticcheck.io/docs/checks#%s",
+		Rules: []checkers.Rule{
+			{ID: "staticcheck", Description: "Bugs and invalid function arguments", HelpURI: "https://github.com/dominikh/go-staticcheck"},
+		},
+	})
+	checkers.RegisterChecker("gostaticcheck", Check{})
+}
+
 // Check runs gostaticcheck for pkgs
 func (c Check) Check(pkgs ...string) error {
-	return checkers.Lint("staticcheck", "", "honnef.co/go/tools/cmd/staticcheck", pkgs, c.Args()...)
+	return c.CheckContext(context.Background(), pkgs...)
+}
+
+// CheckContext runs gostaticcheck for pkgs, stopping if ctx is done.
+func (c Check) CheckContext(ctx context.Context, pkgs ...string) error {
+	return checkers.LintBinary(ctx, Binary, pkgs, append([]string{"-checks", checks}, c.Args()...)...)
 }
 
+// Version returns the pinned version of staticcheck and the checks it runs. It
+// is part of the key used to cache results.
+func (Check) Version() (string, error) { return Binary.String() + " " + checks, nil }
+
 // Args returns command line arguments used for staticcheck
 func (c Check) Args() []string {
 	var args []string
@@ -24,3 +62,6 @@ func (c Check) Args() []string {
 	}
 	return args
 }
+
+// Binaries returns the pinned version of staticcheck. It is used by lint.Prefetch.
+func (Check) Binaries() []checkers.Binary { return []checkers.Binary{Binary} }
@@ -52,6 +52,18 @@ func TestFunc() {
 			Validate: testutil.Contains(
 				" error parsing regexp: missing closing ): `foo(`"),
 		},
+		{
+			// Simplifications are reported by gosimple.
+			Checker: gostaticcheck.Check{},
+			Content: []byte(`package gostaticchecktest
+
+func TestFunc() {
+	for _ = range []string{"a", "b"} {
+	}
+}
+`),
+			Validate: testutil.NoError,
+		},
 	},
 	)
 }
@@ -1,71 +1,260 @@
+// Package govet runs the analyzers of go vet in-process.
 package govet
 
 import (
-	"os/exec"
+	"context"
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/appends"
+	"golang.org/x/tools/go/analysis/passes/asmdecl"
+	"golang.org/x/tools/go/analysis/passes/assign"
+	"golang.org/x/tools/go/analysis/passes/atomic"
+	"golang.org/x/tools/go/analysis/passes/bools"
+	"golang.org/x/tools/go/analysis/passes/buildtag"
+	"golang.org/x/tools/go/analysis/passes/cgocall"
+	"golang.org/x/tools/go/analysis/passes/composite"
+	"golang.org/x/tools/go/analysis/passes/copylock"
+	"golang.org/x/tools/go/analysis/passes/defers"
+	"golang.org/x/tools/go/analysis/passes/directive"
+	"golang.org/x/tools/go/analysis/passes/errorsas"
+	"golang.org/x/tools/go/analysis/passes/framepointer"
+	"golang.org/x/tools/go/analysis/passes/hostport"
+	"golang.org/x/tools/go/analysis/passes/httpresponse"
+	"golang.org/x/tools/go/analysis/passes/ifaceassert"
+	"golang.org/x/tools/go/analysis/passes/loopclosure"
+	"golang.org/x/tools/go/analysis/passes/lostcancel"
+	"golang.org/x/tools/go/analysis/passes/nilfunc"
+	"golang.org/x/tools/go/analysis/passes/printf"
+	"golang.org/x/tools/go/analysis/passes/shadow"
+	"golang.org/x/tools/go/analysis/passes/shift"
+	"golang.org/x/tools/go/analysis/passes/sigchanyzer"
+	"golang.org/x/tools/go/analysis/passes/slog"
+	"golang.org/x/tools/go/analysis/passes/stdmethods"
+	"golang.org/x/tools/go/analysis/passes/stdversion"
+	"golang.org/x/tools/go/analysis/passes/stringintconv"
+	"golang.org/x/tools/go/analysis/passes/structtag"
+	"golang.org/x/tools/go/analysis/passes/testinggoroutine"
+	"golang.org/x/tools/go/analysis/passes/tests"
+	"golang.org/x/tools/go/analysis/passes/timeformat"
+	"golang.org/x/tools/go/analysis/passes/unmarshal"
+	"golang.org/x/tools/go/analysis/passes/unreachable"
+	"golang.org/x/tools/go/analysis/passes/unsafeptr"
+	"golang.org/x/tools/go/analysis/passes/unusedresult"
+	"golang.org/x/tools/go/analysis/passes/waitgroup"
 
 	"github.com/surullabs/lint/checkers"
+	"github.com/surullabs/lint/goanalysis"
 )
 
-// Check implements a lint.Checker for the govet command.
+// Analyzers holds the analyzers run by go vet.
+var Analyzers = []*analysis.Analyzer{
+	appends.Analyzer,
+	asmdecl.Analyzer,
+	assign.Analyzer,
+	atomic.Analyzer,
+	bools.Analyzer,
+	buildtag.Analyzer,
+	cgocall.Analyzer,
+	composite.Analyzer,
+	copylock.Analyzer,
+	defers.Analyzer,
+	directive.Analyzer,
+	errorsas.Analyzer,
+	framepointer.Analyzer,
+	hostport.Analyzer,
+	httpresponse.Analyzer,
+	ifaceassert.Analyzer,
+	loopclosure.Analyzer,
+	lostcancel.Analyzer,
+	nilfunc.Analyzer,
+	printf.Analyzer,
+	shift.Analyzer,
+	sigchanyzer.Analyzer,
+	slog.Analyzer,
+	stdmethods.Analyzer,
+	stdversion.Analyzer,
+	stringintconv.Analyzer,
+	structtag.Analyzer,
+	testinggoroutine.Analyzer,
+	tests.Analyzer,
+	timeformat.Analyzer,
+	unmarshal.Analyzer,
+	unreachable.Analyzer,
+	unsafeptr.Analyzer,
+	unusedresult.Analyzer,
+	waitgroup.Analyzer,
+}
+
+// Optional holds analyzers that are not run by go vet, but can be enabled
+// using Check.Enable.
+var Optional = []*analysis.Analyzer{
+	shadow.Analyzer,
+}
+
+// Check implements a lint.Checker running the analyzers of go vet, using
+// goanalysis. All packages are loaded and analyzed together, including their
+// test files. Analyzers are named as in go vet, such as printf, copylocks and
+// loopclosure.
 type Check struct {
+	// Args holds go tool vet flags, which are mapped onto the analyzers.
+	// -NAME enables the analyzer NAME and -NAME=false disables it,
+	// -printfuncs=LIST adds LIST to PrintfFuncs and -all is ignored since all
+	// of Analyzers are run by default. Flags may also start with --.
+	//
+	// Deprecated: Use Enable, Disable and PrintfFuncs instead.
 	Args []string
+	// Enable lists analyzers in Optional to run, such as shadow.
+	Enable []string
+	// Disable lists analyzers in Analyzers not to run.
+	Disable []string
+	// PrintfFuncs lists additional print wrappers checked by the printf
+	// analyzer, as accepted by go vet -printf.funcs. They are added to the
+	// funcs flag of printf.Analyzer, which is shared by every check in the
+	// process, so later checks also report them.
+	PrintfFuncs []string
+	// Tags is a list of space separated build tags
+	Tags string
+}
+
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "vet",
+		InformationURI: "https://golang.org/cmd/vet/",
+		RuleURI:        "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/%s",
+		Rules: []checkers.Rule{
+			{ID: "vet", Description: "Suspicious constructs", HelpURI: "https://golang.org/cmd/vet/"},
+		},
+	})
+	checkers.RegisterChecker("govet", Shadow)
 }
 
-// Shadow is a Checker that runs
-// 	 go tool vet --all --shadow.
-var Shadow = Check{Args: []string{"--all", "--shadow"}}
+// Shadow is a Checker that runs the analyzers of go vet along with shadow.
+var Shadow = Check{Enable: []string{"shadow"}}
+
+// funcsMutex guards setting the funcs flag of printf.Analyzer.
+var funcsMutex sync.Mutex
 
-// Check runs go tool vet for pkgs.
+// Check runs the analyzers of go vet for pkgs.
 func (c Check) Check(pkgs ...string) error {
-	var errs []string
-	for _, pkg := range pkgs {
-		// Check files per package. If all files for all packages are
-		// passed in as a glob, it causes incorrect reports as described
-		// in TestGoVetMultiPackage_Issue7. Instead run go vet for each package.
-		errs = append(errs, c.checkPackage(pkg)...)
-	}
-	return checkers.Error(errs...)
+	return c.CheckContext(context.Background(), pkgs...)
 }
 
-func (c Check) checkPackage(pkg string) []string {
-	if strings.HasSuffix(pkg, "...") {
-		return c.checkDir(pkg)
+// Version returns the version of goanalysis, which includes the version of the
+// analyzers. It is part of the key used to cache results.
+func (Check) Version() (string, error) { return goanalysis.Check{}.Version() }
+
+// CheckContext runs the analyzers of go vet for pkgs, stopping if ctx is done.
+func (c Check) CheckContext(ctx context.Context, pkgs ...string) error {
+	c, err := c.withArgs()
+	if err != nil {
+		return &checkers.ToolError{Tool: "vet", Err: err}
 	}
-	files, err := checkers.GoFiles(pkg)
+	analyzers, err := c.Analyzers()
 	if err != nil {
-		return []string{err.Error()}
+		return &checkers.ToolError{Tool: "vet", Err: err}
+	}
+	if len(c.PrintfFuncs) > 0 {
+		funcsMutex.Lock()
+		err = printf.Analyzer.Flags.Set("funcs", strings.Join(c.PrintfFuncs, ","))
+		funcsMutex.Unlock()
+		if err != nil {
+			return &checkers.ToolError{Tool: "vet", Err: fmt.Errorf("invalid printf funcs: %v", err)}
+		}
 	}
-	return c.runVet(files)
+	return goanalysis.Check{Analyzers: analyzers, Tags: c.Tags, Tests: true}.CheckContext(ctx, pkgs...)
 }
 
-func (c Check) runVet(paths []string) []string {
-	if len(paths) == 0 {
-		return nil
+// withArgs returns a copy of c with Args mapped onto Enable, Disable and
+// PrintfFuncs.
+func (c Check) withArgs() (Check, error) {
+	res := c
+	res.Args = nil
+	res.Enable = append([]string{}, c.Enable...)
+	res.Disable = append([]string{}, c.Disable...)
+	res.PrintfFuncs = append([]string{}, c.PrintfFuncs...)
+	for _, arg := range c.Args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		switch {
+		case name == "all":
+		case name == "printfuncs" || name == "printf.funcs":
+			if value == "" {
+				return Check{}, fmt.Errorf("invalid vet flag %q: missing functions", arg)
+			}
+			res.PrintfFuncs = append(res.PrintfFuncs, strings.Split(value, ",")...)
+		case hasValue && value != "true" && value != "false":
+			return Check{}, fmt.Errorf("invalid vet flag %q: must be true or false", arg)
+		case find(Optional, name) != nil:
+			if value != "false" && !contains(res.Enable, name) {
+				res.Enable = append(res.Enable, name)
+			}
+		case find(Analyzers, name) != nil:
+			if value == "false" {
+				res.Disable = append(res.Disable, name)
+			}
+		default:
+			return Check{}, fmt.Errorf("unknown vet flag %q", arg)
+		}
 	}
-	args := append([]string{"tool", "vet"}, append(c.Args, paths...)...)
-	res, err := checkers.Exec(exec.Command("go", args...))
-	if err == nil {
-		return nil
+	return res, nil
+}
+
+// Analyzers returns the analyzers run by c. An error is returned if Enable,
+// Disable or Args hold unknown analyzers.
+func (c Check) Analyzers() ([]*analysis.Analyzer, error) {
+	c, err := c.withArgs()
+	if err != nil {
+		return nil, err
 	}
-	switch res.Code {
-	case 1:
-		return strings.Split(strings.TrimSpace(res.Stderr), "\n")
-	default:
-		return []string{err.Error()}
+	disabled := map[string]bool{}
+	for _, name := range c.Disable {
+		if find(Analyzers, name) == nil {
+			return nil, fmt.Errorf("unknown analyzer %q, must be one of %s", name, names(Analyzers))
+		}
+		disabled[name] = true
 	}
+	var res []*analysis.Analyzer
+	for _, a := range Analyzers {
+		if !disabled[a.Name] {
+			res = append(res, a)
+		}
+	}
+	for _, name := range c.Enable {
+		a := find(Optional, name)
+		if a == nil {
+			return nil, fmt.Errorf("unknown analyzer %q, must be one of %s", name, names(Optional))
+		}
+		res = append(res, a)
+	}
+	return res, nil
 }
 
-func (c Check) checkDir(pkg string) []string {
-	p, err := checkers.Load(pkg)
-	if err != nil {
-		return []string{err.Error()}
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
 	}
-	// Loop through each package since we'd like to ignore directories which have
-	// an _ prefix. In the future this allows us to skip vendor directories as well.
-	var errs []string
-	for _, pkg := range p.Pkgs {
-		errs = append(errs, c.checkPackage(pkg)...)
+	return false
+}
+
+func find(analyzers []*analysis.Analyzer, name string) *analysis.Analyzer {
+	for _, a := range analyzers {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func names(analyzers []*analysis.Analyzer) string {
+	var res []string
+	for _, a := range analyzers {
+		res = append(res, a.Name)
 	}
-	return errs
+	sort.Strings(res)
+	return strings.Join(res, ", ")
 }
@@ -8,7 +8,7 @@ import (
 
 	"path/filepath"
 
-	"github.com/sridharv/fakegopath"
+	"github.com/surullabs/lint"
 	"github.com/surullabs/lint/govet"
 	"github.com/surullabs/lint/testutil"
 )
@@ -25,10 +25,10 @@ func testVetError(err error) error {
 	if len(errs) != 2 {
 		return fmt.Errorf("expected 2 errors, got: %v", err)
 	}
-	if !strings.HasSuffix(errs[0], "unreachable code") {
+	if !strings.Contains(errs[0], "result of fmt.Sprintf call not used") {
 		return err
 	}
-	if !strings.Contains(errs[1], "result of fmt.Sprintf call not used") {
+	if !strings.HasSuffix(errs[1], "unreachable code") {
 		return err
 	}
 	return nil
@@ -68,7 +68,7 @@ func main() {
 }`
 
 func TestGoVetMultiPackage_Issue7(t *testing.T) {
-	tmp, err := fakegopath.NewTemporaryWithFiles("multipkg", []fakegopath.SourceFile{
+	tmp, err := testutil.NewGOPATH("multipkg", []testutil.SourceFile{
 		{Content: []byte(file1), Dest: filepath.Join("root", "package1", "main.go")},
 		{Content: []byte(file2), Dest: filepath.Join("root", "package2", "main.go")},
 		{Content: []byte(file3), Dest: filepath.Join("root", "package3", "main.go")},
@@ -115,7 +115,7 @@ func TestFunc() {
 	fmt.Println("undocumented")
 }
 `),
-			Validate: testutil.Contains("expected declaration, found 'IDENT' sfsff"),
+			Validate: testutil.MatchesRegexp("file.go:6:1: expected declaration, found ('IDENT' )?sfsff"),
 		},
 		{
 			Checker: govet.Check{},
@@ -144,9 +144,10 @@ import (
 )
 
 func TestFunc() (err error) {
-    err = fmt.Println("another")
+    _, err = fmt.Println("another")
     if err != nil {
     	err := fmt.Errorf("some error: %v", err)
+    	fmt.Println(err)
     }
     return err
 }
@@ -156,3 +157,99 @@ func TestFunc() (err error) {
 	})
 
 }
+
+func TestOptions(t *testing.T) {
+	testutil.Test(t, "govettest", []testutil.StaticCheckTest{
+		{
+			Checker: govet.Check{Disable: []string{"unusedresult"}},
+			Content: []byte(`package govettest
+
+import "fmt"
+
+func TestFunc() {
+	fmt.Sprintf("test")
+	return
+	fmt.Println("unreachable")
+}
+`),
+			Validate: testutil.HasSuffix("file.go:8:2: unreachable code"),
+		},
+		{
+			Checker: govet.Check{PrintfFuncs: []string{"Logf"}},
+			Content: []byte(`package govettest
+
+func Logf(format string, args ...interface{}) {}
+
+func TestFunc() {
+	Logf("%d", "one")
+}
+`),
+			Validate: testutil.HasSuffix(`file.go:6:8: govettest.Logf format %d has arg "one" of wrong type string`),
+		},
+		{
+			Checker:  govet.Check{PrintfFuncs: []string{""}},
+			Content:  []byte("package govettest\n"),
+			Validate: testutil.Contains("invalid printf funcs: empty string"),
+		},
+		{
+			Checker: govet.Check{Args: []string{"--all", "--shadow", "-unusedresult=false", "-printfuncs=Warnf"}},
+			Content: []byte(`package govettest
+
+import "fmt"
+
+func Warnf(format string, args ...interface{}) {}
+
+func TestFunc() (err error) {
+	fmt.Sprintf("test")
+	if err != nil {
+		err := fmt.Errorf("wrapped: %v", err)
+		Warnf("%d", err.Error())
+	}
+	return err
+}
+`),
+			Validate: func(err error) error {
+				issues := lint.Issues(err)
+				if len(issues) != 2 || !strings.Contains(issues[0].Message, "declaration of \"err\" shadows") ||
+					!strings.Contains(issues[1].Message, "govettest.Warnf format %d has arg err.Error() of wrong type string") {
+					return fmt.Errorf("unexpected issues %v", issues)
+				}
+				return nil
+			},
+		},
+		{
+			Checker:  govet.Check{Args: []string{"-unknown"}},
+			Content:  []byte("package govettest\n"),
+			Validate: testutil.Contains(`unknown vet flag "-unknown"`),
+		},
+		{
+			Checker:  govet.Check{Disable: []string{"shadow"}},
+			Content:  []byte("package govettest\n"),
+			Validate: testutil.Contains(`unknown analyzer "shadow", must be one of appends, asmdecl,`),
+		},
+		{
+			Checker:  govet.Check{Enable: []string{"printf"}},
+			Content:  []byte("package govettest\n"),
+			Validate: testutil.HasSuffix(`unknown analyzer "printf", must be one of shadow`),
+		},
+	})
+}
+
+func TestTestFiles(t *testing.T) {
+	tmp, err := testutil.NewGOPATH("govettest", []testutil.SourceFile{
+		{Content: []byte("package govettest\n"), Dest: filepath.Join("govettest", "file.go")},
+		{
+			Content: []byte("package govettest\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {\n\tt.Errorf(\"%d\", \"one\")\n}\n"),
+			Dest:    filepath.Join("govettest", "file_test.go"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create temporary go path: %v", err)
+	}
+	defer tmp.Reset()
+
+	err = govet.Check{}.Check("govettest")
+	if issues := lint.Issues(err); len(issues) != 1 || !strings.HasSuffix(issues[0].File, "file_test.go") || issues[0].Line != 6 {
+		t.Fatalf("expected an issue in file_test.go, got: %v", err)
+	}
+}
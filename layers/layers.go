@@ -0,0 +1,236 @@
+// Package layers checks that imports between packages follow the layering of
+// an application.
+//
+//    layers.Check{Rules: []layers.Rule{
+//        {
+//            ID:      "domain",
+//            From:    []string{"example.com/app/internal/domain/..."},
+//            Deny:    []string{"example.com/app/internal/transport/..."},
+//            Message: "the domain must not depend on transports",
+//        },
+//        {
+//            ID:      "wiring",
+//            Except:  []string{"example.com/app/cmd/..."},
+//            Deny:    []string{"example.com/app/wiring/..."},
+//            Message: "only commands may depend on wiring",
+//        },
+//    }}
+package layers
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+// Rule restricts the imports of a set of packages. Packages are matched using
+// import path patterns, as described in checkers.MatchPackage.
+//
+// Deny is checked for imports of the package and the packages it imports,
+// directly or indirectly. Indirect imports are reported along with the path of
+// imports leading to the denied package. Allow is only checked for direct
+// imports.
+type Rule struct {
+	// ID is reported as the RuleID of violations. It defaults to layers.
+	ID string
+	// Message is reported along with each violation.
+	Message string
+	// From lists the packages the rule applies to. It applies to all packages
+	// if empty.
+	From []string
+	// Except lists packages the rule does not apply to.
+	Except []string
+	// Deny lists packages that must not be imported.
+	Deny []string
+	// Allow lists the only packages outside the standard library that may be
+	// imported, if it is not empty.
+	Allow []string
+}
+
+// Check implements lint.Checker by checking Rules for the imports of packages.
+type Check struct {
+	Rules []Rule
+}
+
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "layers",
+		InformationURI: "https://godoc.org/github.com/surullabs/lint/layers",
+	})
+	checkers.RegisterChecker("layers", Check{})
+}
+
+// Check reports imports in pkgs violating Rules. Each violation is reported at
+// the import spec in the checked package, including files that import "C", or
+// at the directory of the package if no import spec is found.
+func (c Check) Check(pkgs ...string) error {
+	if len(c.Rules) == 0 {
+		return nil
+	}
+	g, err := checkers.LoadImports(pkgs...)
+	if err != nil {
+		return &checkers.ToolError{Tool: "layers", Err: err}
+	}
+	var issues []checkers.Issue
+	for _, root := range g.Roots {
+		pkg := g.Packages[root]
+		for _, r := range c.Rules {
+			if !r.appliesTo(root) {
+				continue
+			}
+			violations := map[string]string{}
+			for _, imp := range pkg.Imports {
+				if msg := r.violation(g, root, imp); msg != "" {
+					violations[imp] = msg
+				}
+			}
+			if len(violations) == 0 {
+				continue
+			}
+			found, err := reportImports(pkg, r.id(), violations)
+			if err != nil {
+				return &checkers.ToolError{Tool: "layers", Err: err}
+			}
+			issues = append(issues, found...)
+		}
+	}
+	return checkers.Issues(issues...)
+}
+
+// Version returns the version of this module. It is part of the key used to
+// cache results.
+func (Check) Version() (string, error) {
+	return checkers.ModuleVersion("github.com/surullabs/lint"), nil
+}
+
+func (r Rule) id() string {
+	if r.ID == "" {
+		return "layers"
+	}
+	return r.ID
+}
+
+func (r Rule) appliesTo(pkg string) bool {
+	return (len(r.From) == 0 || matchAny(r.From, pkg)) && !matchAny(r.Except, pkg)
+}
+
+// violation returns a description of the violation of r caused by pkg
+// importing imp, or an empty string if there is none.
+func (r Rule) violation(g *checkers.ImportGraph, pkg, imp string) string {
+	msg := ""
+	if path := denied(g, imp, r.Deny); path != nil {
+		msg = fmt.Sprintf("%s imports %s", pkg, path[len(path)-1])
+		if len(path) > 1 {
+			msg += " via " + strings.Join(path[:len(path)-1], " -> ")
+		}
+	} else if len(r.Allow) > 0 && !matchAny(r.Allow, imp) && !standard(g, imp) {
+		msg = fmt.Sprintf("%s imports %s, which is not allowed", pkg, imp)
+	} else {
+		return ""
+	}
+	if r.Message != "" {
+		msg += ": " + r.Message
+	}
+	return msg
+}
+
+func standard(g *checkers.ImportGraph, path string) bool {
+	p := g.Packages[path]
+	return p != nil && p.Standard
+}
+
+// denied returns the shortest path of imports from imp to a package matching
+// deny, starting with imp, or nil if there is none. The imports of standard
+// library packages are not followed.
+func denied(g *checkers.ImportGraph, imp string, deny []string) []string {
+	if len(deny) == 0 {
+		return nil
+	}
+	prev := map[string]string{imp: ""}
+	queue := []string{imp}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if matchAny(deny, cur) {
+			var path []string
+			for p := cur; p != ""; p = prev[p] {
+				path = append([]string{p}, path...)
+			}
+			return path
+		}
+		p := g.Packages[cur]
+		if p == nil || p.Standard {
+			continue
+		}
+		for _, next := range p.Imports {
+			if _, ok := prev[next]; !ok {
+				prev[next] = cur
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// reportImports returns an issue for each import spec in pkg importing a
+// package in violations, which maps import paths to messages. Violations
+// without an import spec, such as imports added by go list, are reported
+// against the directory of pkg.
+func reportImports(pkg *checkers.ImportedPackage, rule string, violations map[string]string) ([]checkers.Issue, error) {
+	var issues []checkers.Issue
+	reported := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, file := range append(append([]string{}, pkg.GoFiles...), pkg.CgoFiles...) {
+		f, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse imports: %v", err)
+		}
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			if mapped, ok := pkg.ImportMap[path]; ok {
+				path = mapped
+			}
+			if msg, ok := violations[path]; ok {
+				issue := checkers.ParseIssue(fset.Position(spec.Pos()).String() + ": " + msg)
+				issue.RuleID = rule
+				issues = append(issues, issue)
+				reported[path] = true
+			}
+		}
+	}
+	var missing []string
+	for path := range violations {
+		if !reported[path] {
+			missing = append(missing, path)
+		}
+	}
+	sort.Strings(missing)
+	for _, path := range missing {
+		issues = append(issues, checkers.Issue{
+			File:     pkg.Dir,
+			Severity: checkers.SeverityError,
+			RuleID:   rule,
+			Message:  violations[path],
+			Raw:      pkg.Dir + ": " + violations[path],
+		})
+	}
+	return issues, nil
+}
+
+// matchAny returns true if path matches any of patterns.
+func matchAny(patterns []string, path string) bool {
+	for _, p := range patterns {
+		if checkers.MatchPackage(p, path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package layers_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+	"github.com/surullabs/lint/layers"
+	"github.com/surullabs/lint/testutil"
+)
+
+func source(dest, content string) testutil.SourceFile {
+	return testutil.SourceFile{Content: []byte(content), Dest: filepath.FromSlash(dest)}
+}
+
+func TestLayers(t *testing.T) {
+	tmp, err := testutil.NewGOPATH("layerstest", []testutil.SourceFile{
+		source("app/domain/domain.go", `package domain
+
+import (
+	"fmt"
+
+	"app/util"
+	"app/wiring"
+)
+
+var _ = fmt.Sprint(util.X, wiring.X)
+`),
+		source("app/domain/other.go", `package domain
+
+import _ "app/transport"
+`),
+		source("app/util/util.go", "package util\n\nimport \"app/transport\"\n\nvar X = transport.X\n"),
+		source("app/transport/transport.go", "package transport\n\nvar X = 1\n"),
+		source("app/wiring/wiring.go", "package wiring\n\nvar X = 1\n"),
+		source("app/native/native.go", "package native\n\n// int one() { return 1; }\nimport \"C\"\n\nimport \"app/transport\"\n\nvar X = int(C.one()) + transport.X\n"),
+		source("app/cmd/app/main.go", "package main\n\nimport \"app/wiring\"\n\nvar _ = wiring.X\n\nfunc main() {}\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tmp.Reset()
+	defer checkers.Unload("app/...")
+	// Files importing "C" are only listed by go list if cgo is enabled.
+	t.Setenv("CGO_ENABLED", "1")
+
+	for _, test := range []struct {
+		rules    []layers.Rule
+		expected []string
+	}{
+		{nil, nil},
+		{
+			[]layers.Rule{{ID: "domain", From: []string{"app/domain/..."}, Deny: []string{"app/transport"}, Message: "keep the domain independent"}},
+			[]string{
+				"domain app/domain/domain.go:6:2: app/domain imports app/transport via app/util: keep the domain independent",
+				"domain app/domain/other.go:3:8: app/domain imports app/transport: keep the domain independent",
+			},
+		},
+		{
+			[]layers.Rule{{Except: []string{"app/cmd/..."}, Deny: []string{"app/wiring"}}},
+			[]string{"layers app/domain/domain.go:7:2: app/domain imports app/wiring"},
+		},
+		{
+			[]layers.Rule{{From: []string{"app/native"}, Deny: []string{"app/transport"}}},
+			[]string{"layers app/native/native.go:6:8: app/native imports app/transport"},
+		},
+		{
+			[]layers.Rule{{From: []string{"app/util", "app/domain"}, Allow: []string{"app/util"}}},
+			[]string{
+				"layers app/domain/domain.go:7:2: app/domain imports app/wiring, which is not allowed",
+				"layers app/domain/other.go:3:8: app/domain imports app/transport, which is not allowed",
+				"layers app/util/util.go:3:8: app/util imports app/transport, which is not allowed",
+			},
+		},
+	} {
+		var found []string
+		for _, issue := range lint.Issues(layers.Check{Rules: test.rules}.Check("app/...")) {
+			rel, err := filepath.Rel(filepath.Join(tmp.Path, "src"), issue.File)
+			if err != nil {
+				t.Fatal(err)
+			}
+			found = append(found, fmt.Sprintf("%s %s:%d:%d: %s", issue.RuleID, filepath.ToSlash(rel), issue.Line, issue.Column, issue.Message))
+		}
+		if strings.Join(found, "\n") != strings.Join(test.expected, "\n") {
+			t.Errorf("%v: expected\n%s\ngot\n%s", test.rules, strings.Join(test.expected, "\n"), strings.Join(found, "\n"))
+		}
+	}
+}
+
+func TestMatchPackage(t *testing.T) {
+	for _, test := range []struct {
+		pattern, path string
+		match         bool
+	}{
+		{"net/http", "net/http", true},
+		{"net/http", "net/http/httptest", false},
+		{"net/...", "net", true},
+		{"net/...", "net/http", true},
+		{"net/...", "network", false},
+		{".../internal/...", "example.com/app/internal/domain", true},
+		{"example.com/.../domain", "example.com/app/internal/domain", true},
+		{"example.com/.../domain", "example.com/app/domain/x", false},
+	} {
+		if checkers.MatchPackage(test.pattern, test.path) != test.match {
+			t.Errorf("%s %s: expected %v", test.pattern, test.path, test.match)
+		}
+	}
+}
@@ -6,7 +6,11 @@
 package lint
 
 import (
+	"context"
 	"reflect"
+	"runtime"
+	"sync"
+	"time"
 
 	"github.com/surullabs/lint/checkers"
 	"github.com/surullabs/lint/errcheck"
@@ -19,8 +23,8 @@ import (
 
 // Default holds a default list of lint tools for convenient use. These include
 //
-//     - gofmt -d
-//     - go tool vet -shadow
+//     - gofmt -d, run in-process
+//     - go vet, along with shadow
 //     - golint
 //     - gosimple (https://github.com/dominikh/go-simple)
 //     - gostaticcheck (https://github.com/dominikh/go-staticcheck)
@@ -38,6 +42,10 @@ type errors interface {
 	Errors() []string
 }
 
+type issues interface {
+	Issues() []checkers.Issue
+}
+
 // Checker is the interface that wraps the Check method.
 //
 // Check lints all files in pkgs. Each item in pkgs may be a fully
@@ -47,6 +55,16 @@ type Checker interface {
 	Check(pkgs ...string) error
 }
 
+// ContextChecker is a Checker that can be stopped using a context.
+//
+// CheckContext is identical to Check, except that it stops checking and
+// reports an error naming the package being checked if ctx is done before it
+// completes. Group prefers CheckContext over Check when a Checker implements it.
+type ContextChecker interface {
+	Checker
+	CheckContext(ctx context.Context, pkgs ...string) error
+}
+
 // Group is a Checker list that is applied in sequence. See Check for details on
 // how it is applied.
 type Group []Checker
@@ -66,26 +84,194 @@ type Group []Checker
 // A checker is not shorted-circuited by a previous checker returning an error.
 //
 // Any error that implements errors is flattened into the final error list.
+//
+// The returned error also exposes each error as a checkers.Issue, with the
+// Checker field set to the type of the Checker unless the Checker already set it.
+// Use Issues to retrieve them.
+//
+// Issues suppressed by //lint:ignore and //lint:file-ignore directives in the
+// source files of pkgs are skipped, and malformed or unused directives naming
+// checkers in g are reported. See SkipDirectives for details. Use Failures to
+// tell checkers that failed to run apart from those that reported issues.
 func (g Group) Check(pkgs ...string) error {
-	var errs []string
-	for _, checker := range g {
-		name := reflect.TypeOf(checker).String()
-		switch err := checker.Check(pkgs...).(type) {
-		case nil:
-			continue
-		case errors:
-			cerrs := err.Errors()
-			for _, e := range cerrs {
-				errs = append(errs, name+": "+e)
+	return g.CheckParallelContext(context.Background(), 1, pkgs...)
+}
+
+// CheckContext is identical to Check, except that ctx is passed to each
+// Checker that implements ContextChecker.
+func (g Group) CheckContext(ctx context.Context, pkgs ...string) error {
+	return g.CheckParallelContext(ctx, 1, pkgs...)
+}
+
+// CheckParallel is identical to Check, except that up to n checkers are run
+// concurrently. If n <= 0, runtime.NumCPU() is used instead.
+//
+// The returned error lists errors in the same order as Check, irrespective of
+// the order in which the checkers complete.
+func (g Group) CheckParallel(n int, pkgs ...string) error {
+	return g.CheckParallelContext(context.Background(), n, pkgs...)
+}
+
+// CheckParallelContext is identical to CheckParallel, except that ctx is passed
+// to each Checker that implements ContextChecker.
+func (g Group) CheckParallelContext(ctx context.Context, n int, pkgs ...string) error {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	// Cached checkers hash the packages once for all checkers.
+	ctx = withSharedHashes(ctx)
+	results := make([][]checkers.Issue, len(g))
+	errs := make([]error, len(g))
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, checker := range g {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, checker Checker) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = check(ctx, checker, pkgs)
+			results[i] = namedIssues(checker, errs[i])
+		}(i, checker)
+	}
+	wg.Wait()
+	var all []checkers.Issue
+	ran := []string{}
+	var failures []Failure
+	for i, r := range results {
+		all = append(all, r...)
+		if failed(errs[i]) {
+			failures = append(failures, Failure{Checker: CheckerName(g[i]), Err: errs[i]})
+		} else {
+			ran = append(ran, CheckerName(g[i]))
+		}
+	}
+	// Packages that cannot be loaded are reported by the checkers.
+	if d, err := loadDirectives(pkgs); err == nil {
+		all = d.apply(all, ran)
+	}
+	return groupIssues(all, failures)
+}
+
+// groupIssues returns an error holding issues and failures, as returned by
+// Group.Check. If issues is empty, the output of the failures is used instead,
+// so that failures are reported even if their output was skipped.
+func groupIssues(issues []checkers.Issue, failures []Failure) error {
+	if len(failures) == 0 {
+		return checkers.Issues(issues...)
+	}
+	if len(issues) == 0 {
+		for _, f := range failures {
+			for _, issue := range Issues(f.Err) {
+				if issue.Checker == "" {
+					issue.Checker = f.Checker
+				}
+				issues = append(issues, issue)
 			}
-		default:
-			errs = append(errs, name+": "+err.Error())
 		}
 	}
-	if len(errs) == 0 {
+	return &groupError{issueError: checkers.Issues(issues...).(issueError), failures: failures}
+}
+
+// failed returns true if err reports that a Checker failed to run, rather
+// than issues it found.
+func failed(err error) bool {
+	switch err.(type) {
+	case *checkers.InstallError, *checkers.ToolError:
+		return true
+	}
+	return false
+}
+
+type issueError interface {
+	error
+	issues
+	errors
+}
+
+// groupError holds the issues returned by Group.Check along with the
+// checkers that failed.
+type groupError struct {
+	issueError
+	failures []Failure
+}
+
+// Failure describes a Checker that failed to run.
+type Failure struct {
+	// Checker is the name of the Checker, as returned by CheckerName.
+	Checker string
+	// Err is the error returned by the Checker.
+	Err error
+}
+
+// Failures returns the checkers that failed to run, in the order of the
+// Group, if err was returned by Group.Check. A Checker fails if it returns a
+// *checkers.InstallError, such as when its linter cannot be installed, or a
+// *checkers.ToolError, such as when its linter crashes or packages cannot be
+// loaded. The output of failed checkers is also included in the issues
+// returned by Issues.
+func Failures(err error) []Failure {
+	if g, ok := err.(*groupError); ok {
+		return g.failures
+	}
+	return nil
+}
+
+func check(ctx context.Context, checker Checker, pkgs []string) error {
+	if c, ok := checker.(ContextChecker); ok {
+		return c.CheckContext(ctx, pkgs...)
+	}
+	return checker.Check(pkgs...)
+}
+
+// namedIssues returns the issues in err with the Checker field set to the name
+// of checker unless it is already set.
+func namedIssues(checker Checker, err error) []checkers.Issue {
+	name := CheckerName(checker)
+	var res []checkers.Issue
+	for _, issue := range Issues(err) {
+		if issue.Checker == "" {
+			issue.Checker = name
+		}
+		res = append(res, issue)
+	}
+	return res
+}
+
+// CheckerName returns the name used for issues reported by checker, which is
+// the type of checker. A Timeout or Cached is named after the Checker it wraps.
+func CheckerName(checker Checker) string {
+	switch c := checker.(type) {
+	case Timeout:
+		return CheckerName(c.Checker)
+	case Cached:
+		return CheckerName(c.Checker)
+	}
+	return reflect.TypeOf(checker).String()
+}
+
+// Issues returns the issues contained in err. If err was returned by Group.Check
+// or checkers.Issues the issues are returned unmodified. Otherwise each string
+// returned by the errors interface described in Skip, or the error string if err
+// does not implement it, is converted using checkers.ParseIssue.
+func Issues(err error) []checkers.Issue {
+	switch e := err.(type) {
+	case nil:
 		return nil
+	case issues:
+		return e.Issues()
+	case errors:
+		errs := e.Errors()
+		res := make([]checkers.Issue, len(errs))
+		for i, str := range errs {
+			res[i] = checkers.ParseIssue(str)
+		}
+		return res
+	default:
+		return []checkers.Issue{checkers.ParseIssue(e.Error())}
 	}
-	return checkers.Error(errs...)
 }
 
 // With returns a copy of g with checkers appended
@@ -94,3 +280,12 @@ func (g Group) With(checkers ...Checker) Group {
 	copy(copied, g)
 	return Group(append(copied, checkers...))
 }
+
+// Timeout returns a copy of g with each Checker wrapped in a Timeout of d.
+func (g Group) Timeout(d time.Duration) Group {
+	wrapped := make([]Checker, len(g))
+	for i, c := range g {
+		wrapped[i] = Timeout{Checker: c, Duration: d}
+	}
+	return Group(wrapped)
+}
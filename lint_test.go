@@ -1,6 +1,7 @@
 package lint_test
 
 import (
+	"context"
 	"testing"
 
 	"log"
@@ -10,6 +11,8 @@ import (
 	"runtime/debug"
 
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/surullabs/lint"
 	"github.com/surullabs/lint/checkers"
@@ -85,6 +88,119 @@ func TestGroup(t *testing.T) {
 		fmt.Sprintf("%v", err))
 }
 
+func TestGroupParallel(t *testing.T) {
+	var running, maxRunning int32
+	delayed := func(d time.Duration, errs ...string) lint.Checker {
+		return checkFn(func(...string) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(d)
+			return checkers.Error(errs...)
+		})
+	}
+	g := lint.Group{
+		delayed(30*time.Millisecond, "err1"),
+		delayed(20 * time.Millisecond),
+		delayed(10*time.Millisecond, "err2", "err3"),
+		delayed(0, "err4"),
+	}
+	expected := "lint_test.checkFn: err1\nlint_test.checkFn: err2\nlint_test.checkFn: err3\nlint_test.checkFn: err4"
+
+	err := g.CheckParallel(2, "./...")
+	assert(t, err != nil && err.Error() == expected, fmt.Sprintf("%v", err))
+	assert(t, maxRunning == 2, fmt.Sprintf("expected 2 concurrent checkers, got %d", maxRunning))
+
+	maxRunning = 0
+	err = g.CheckParallel(1, "./...")
+	assert(t, err != nil && err.Error() == expected, fmt.Sprintf("%v", err))
+	assert(t, maxRunning == 1, fmt.Sprintf("expected 1 concurrent checker, got %d", maxRunning))
+
+	err = lint.Group{expectRecursive}.CheckParallel(0, "./...")
+	assert(t, err == nil, fmt.Sprintf("%v", err))
+}
+
+type ctxCheckFn func(ctx context.Context, pkgs ...string) error
+
+func (c ctxCheckFn) Check(pkgs ...string) error { return c(context.Background(), pkgs...) }
+
+func (c ctxCheckFn) CheckContext(ctx context.Context, pkgs ...string) error { return c(ctx, pkgs...) }
+
+// hangingLinter starts a process tree that runs for much longer than any test timeout.
+var hangingLinter = ctxCheckFn(func(ctx context.Context, pkgs ...string) error {
+	return checkers.LintContext(ctx, "sh", "", "sh", pkgs, "-c", "sleep 30 & sleep 30")
+})
+
+func TestTimeout(t *testing.T) {
+	slow := checkFn(func(...string) error {
+		time.Sleep(time.Second)
+		return checkers.Error("err1")
+	})
+	err := lint.Group{slow, twoErrors}.Timeout(20 * time.Millisecond).Check("./...")
+	assert(t,
+		err != nil && err.Error() == "lint_test.checkFn: timed out after 20ms checking ./...\n"+
+			"lint_test.checkFn: err1\nlint_test.checkFn: err2",
+		fmt.Sprintf("%v", err))
+
+	start := time.Now()
+	err = lint.Group{lint.Timeout{Checker: hangingLinter, Duration: 100 * time.Millisecond}}.Check(".")
+	assert(t,
+		err != nil && err.Error() == "lint_test.ctxCheckFn: sh: stopped checking .: context deadline exceeded",
+		fmt.Sprintf("%v", err))
+	assert(t, time.Since(start) < 10*time.Second, "linter process tree was not killed")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = lint.Group{hangingLinter, expectRecursive}.CheckContext(ctx, ".")
+	assert(t,
+		err != nil && err.Error() == "lint_test.ctxCheckFn: sh: stopped checking .: context canceled\n"+
+			"lint_test.checkFn: expected [./...], got [.]",
+		fmt.Sprintf("%v", err))
+}
+
+func TestIssues(t *testing.T) {
+	located := checkFn(func(...string) error {
+		return checkers.Error("file.go:23: err is shadowed", "file.go:6:1: bad call (SA1000)")
+	})
+	err := lint.Group{located, ungroupedError}.Check("./...")
+	assert(t,
+		err != nil && err.Error() == "lint_test.checkFn: file.go:23: err is shadowed\n"+
+			"lint_test.checkFn: file.go:6:1: bad call (SA1000)\nlint_test.checkFn: ungrouped: 1",
+		fmt.Sprintf("%v", err))
+
+	expected := []checkers.Issue{
+		{
+			Checker: "lint_test.checkFn", File: "file.go", Line: 23, Severity: checkers.SeverityError,
+			Message: "err is shadowed", Raw: "file.go:23: err is shadowed",
+		},
+		{
+			Checker: "lint_test.checkFn", File: "file.go", Line: 6, Column: 1, Severity: checkers.SeverityError,
+			RuleID: "SA1000", Message: "bad call", Raw: "file.go:6:1: bad call (SA1000)",
+		},
+		{
+			Checker: "lint_test.checkFn", Severity: checkers.SeverityError,
+			Message: "ungrouped: 1", Raw: "ungrouped: 1",
+		},
+	}
+	issues := lint.Issues(err)
+	assert(t, reflect.DeepEqual(issues, expected), fmt.Sprintf("%#v", issues))
+
+	// Skipping retains issues
+	issues = lint.Issues(lint.Skip(err, lint.RegexpMatch(`shadowed`)))
+	assert(t, reflect.DeepEqual(issues, expected[1:]), fmt.Sprintf("%#v", issues))
+
+	// Issues are created for plain errors
+	issues = lint.Issues(twoErrors.Check())
+	assert(t, len(issues) == 2 && issues[1].Message == "err2" && issues[1].Checker == "",
+		fmt.Sprintf("%#v", issues))
+	assert(t, lint.Issues(nil) == nil, "expected no issues")
+}
+
 type skipFunc func(err string) bool
 
 func (s skipFunc) Skip(err string) bool { return s(err) }
@@ -0,0 +1,57 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+// Installer is implemented by checkers that run linters installed from pinned
+// module versions. Binaries returns the linters the checker runs.
+type Installer interface {
+	Binaries() []checkers.Binary
+}
+
+// Prefetch installs every linter needed by the checkers in g into dir, so that
+// dir can be copied to machines without network access. Use it there by
+// setting the LINT_BIN environment variable to dir, along with LINT_OFFLINE=1
+// to ensure that nothing is downloaded. See checkers.Offline for details.
+//
+// Checkers wrapped in a Timeout or Cached are included. Each linter is
+// installed even if installing another fails. The errors for linters that
+// could not be installed are returned together.
+func Prefetch(dir string, g Group) error {
+	seen := map[string]bool{}
+	var errs []string
+	for _, c := range g {
+		i, ok := unwrap(c).(Installer)
+		if !ok {
+			continue
+		}
+		for _, b := range i.Binaries() {
+			if seen[b.String()] {
+				continue
+			}
+			seen[b.String()] = true
+			if _, err := b.InstallIn(dir); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to prefetch linters:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// unwrap returns the checker wrapped by c if it is a Timeout or Cached.
+func unwrap(checker Checker) Checker {
+	switch c := checker.(type) {
+	case Timeout:
+		return unwrap(c.Checker)
+	case Cached:
+		return unwrap(c.Checker)
+	}
+	return checker
+}
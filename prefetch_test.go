@@ -0,0 +1,53 @@
+package lint_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+)
+
+type installChecker struct {
+	bin checkers.Binary
+}
+
+func (installChecker) Check(pkgs ...string) error { return nil }
+
+func (c installChecker) Binaries() []checkers.Binary { return []checkers.Binary{c.bin} }
+
+func TestPrefetch(t *testing.T) {
+	defer func(offline bool) { checkers.Offline = offline }(checkers.Offline)
+	checkers.Offline = true
+	t.Setenv("GOPROXY", "https://proxy.golang.org")
+	t.Setenv("GOFLAGS", "")
+
+	dir := t.TempDir()
+	tool := checkers.Binary{Name: "tool", Package: "example.com/tool", Module: "example.com/tool", Version: "v1.0.0"}
+	other := checkers.Binary{Name: "other", Package: "example.com/other/cmd/other", Module: "example.com/other", Version: "v0.1.0"}
+	g := lint.Group{
+		installChecker{tool},
+		lint.Timeout{Checker: installChecker{other}, Duration: time.Minute},
+		lint.Cached{Checker: installChecker{tool}, Cache: &lint.Cache{Dir: t.TempDir()}},
+		lint.Group{},
+	}
+	if err := lint.Prefetch(dir, lint.Group{lint.Group{}}); err != nil {
+		t.Fatal(err)
+	}
+	err := lint.Prefetch(dir, g)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	msg := err.Error()
+	// Each linter is attempted once, even if it fails.
+	if n := strings.Count(msg, "go install example.com/tool@v1.0.0"); n != 1 {
+		t.Errorf("tool attempted %d times: %v", n, err)
+	}
+	if !strings.Contains(msg, "go install example.com/other/cmd/other@v0.1.0") {
+		t.Errorf("other not attempted: %v", err)
+	}
+	if !strings.Contains(msg, dir) {
+		t.Errorf("not installed in %s: %v", dir, err)
+	}
+}
@@ -0,0 +1,278 @@
+// Package report writes lint results in machine readable formats.
+//
+// Text, JSON, Checkstyle XML, JUnit XML and SARIF are supported.
+//
+// Each Format writes the issues contained in an error returned by
+// lint.Group.Check. See lint.Issues for details on how issues are extracted.
+// WriteFile can be used from a test to write a report alongside the regular
+// test output.
+//
+//    func TestLint(t *testing.T) {
+//    	err := lint.Default.Check("./...")
+//    	if rerr := report.WriteFile("lint.xml", report.Checkstyle, err); rerr != nil {
+//    		t.Fatal(rerr)
+//    	}
+//    	if err != nil {
+//    		t.Fatal(err)
+//    	}
+//    }
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+)
+
+// Format writes the issues in err to w.
+type Format func(w io.Writer, err error) error
+
+// Formats holds all available formats keyed by name.
+var Formats = map[string]Format{
+	"text":       Text,
+	"json":       JSON,
+	"checkstyle": Checkstyle,
+	"junit":      JUnit,
+	"sarif":      SARIF,
+}
+
+// WriteFile writes the issues in err to the file at path using format.
+func WriteFile(path string, format Format, err error) error {
+	f, ferr := os.Create(path)
+	if ferr != nil {
+		return fmt.Errorf("failed to create report: %v", ferr)
+	}
+	if werr := format(f, err); werr != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write report %s: %v", path, werr)
+	}
+	if cerr := f.Close(); cerr != nil {
+		return fmt.Errorf("failed to write report %s: %v", path, cerr)
+	}
+	return nil
+}
+
+// Text writes one issue per line, in the same form as the error returned by
+// lint.Group.Check.
+func Text(w io.Writer, err error) error {
+	for _, issue := range lint.Issues(err) {
+		if _, werr := fmt.Fprintln(w, issue.String()); werr != nil {
+			return werr
+		}
+	}
+	return nil
+}
+
+type group struct {
+	name   string
+	issues []checkers.Issue
+}
+
+// groupBy groups issues by key in the order in which each key first occurs.
+func groupBy(issues []checkers.Issue, key func(checkers.Issue) string) []group {
+	var groups []group
+	index := map[string]int{}
+	for _, issue := range issues {
+		k := key(issue)
+		i, ok := index[k]
+		if !ok {
+			i, index[k] = len(groups), len(groups)
+			groups = append(groups, group{name: k})
+		}
+		groups[i].issues = append(groups[i].issues, issue)
+	}
+	return groups
+}
+
+func byFile(issue checkers.Issue) string    { return issue.File }
+func byChecker(issue checkers.Issue) string { return issue.Checker }
+
+type jsonIssue struct {
+	Checker  string `json:"checker"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	RuleID   string `json:"rule,omitempty"`
+	Message  string `json:"message"`
+	Raw      string `json:"raw"`
+}
+
+type jsonReport struct {
+	Total    int            `json:"total"`
+	Checkers map[string]int `json:"checkers"`
+	Files    map[string]int `json:"files"`
+	Issues   []jsonIssue    `json:"issues"`
+}
+
+// JSON writes an object holding every issue along with the number of issues
+// reported by each checker and for each file. Issues without a file are not
+// included in the file counts.
+func JSON(w io.Writer, err error) error {
+	issues := lint.Issues(err)
+	r := jsonReport{
+		Total:    len(issues),
+		Checkers: map[string]int{},
+		Files:    map[string]int{},
+		Issues:   []jsonIssue{},
+	}
+	for _, issue := range issues {
+		r.Checkers[issue.Checker]++
+		if issue.File != "" {
+			r.Files[issue.File]++
+		}
+		r.Issues = append(r.Issues, jsonIssue{
+			Checker:  issue.Checker,
+			File:     issue.File,
+			Line:     issue.Line,
+			Column:   issue.Column,
+			Severity: string(issue.Severity),
+			RuleID:   issue.RuleID,
+			Message:  issue.Message,
+			Raw:      issue.Raw,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// Checkstyle writes issues grouped by file in the Checkstyle XML format. The
+// checker is used as the source of each error. Issues without a file are
+// grouped under a file with an empty name.
+func Checkstyle(w io.Writer, err error) error {
+	r := checkstyleReport{Version: "5.0"}
+	for _, g := range groupBy(lint.Issues(err), byFile) {
+		f := checkstyleFile{Name: g.name}
+		for _, issue := range g.issues {
+			f.Errors = append(f.Errors, checkstyleError{
+				Line:     issue.Line,
+				Column:   issue.Column,
+				Severity: severity(issue),
+				Message:  issue.Message,
+				Source:   source(issue),
+			})
+		}
+		r.Files = append(r.Files, f)
+	}
+	return writeXML(w, r)
+}
+
+type junitReport struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string       `xml:"name,attr"`
+	ClassName string       `xml:"classname,attr"`
+	Failure   junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit writes issues in the JUnit XML format. Each checker is written as a
+// test suite containing a failed test case for each file with issues. The
+// failure lists every issue in the file. Issues without a file are listed in a
+// test case named after the checker.
+func JUnit(w io.Writer, err error) error {
+	var r junitReport
+	for _, c := range groupBy(lint.Issues(err), byChecker) {
+		s := junitSuite{Name: c.name}
+		for _, f := range groupBy(c.issues, byFile) {
+			text := ""
+			for _, issue := range f.issues {
+				text += issue.Raw + "\n"
+			}
+			name := f.name
+			if name == "" {
+				name = c.name
+			}
+			s.Cases = append(s.Cases, junitCase{
+				Name:      name,
+				ClassName: c.name,
+				Failure: junitFailure{
+					Message: fmt.Sprintf("%d issue(s)", len(f.issues)),
+					Type:    severity(f.issues[0]),
+					Text:    text,
+				},
+			})
+		}
+		s.Tests, s.Failures = len(s.Cases), len(s.Cases)
+		r.Tests, r.Failures = r.Tests+s.Tests, r.Failures+s.Failures
+		r.Suites = append(r.Suites, s)
+	}
+	return writeXML(w, r)
+}
+
+func severity(issue checkers.Issue) string {
+	if issue.Severity == "" {
+		return string(checkers.SeverityError)
+	}
+	return string(issue.Severity)
+}
+
+func source(issue checkers.Issue) string {
+	if issue.RuleID == "" {
+		return issue.Checker
+	}
+	return issue.Checker + "." + issue.RuleID
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, data)
+	return err
+}
+
+// Names returns the names of all available formats in sorted order.
+func Names() []string {
+	var names []string
+	for name := range Formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
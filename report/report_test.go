@@ -0,0 +1,168 @@
+package report_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/surullabs/lint/checkers"
+	"github.com/surullabs/lint/report"
+)
+
+var issues = checkers.Issues(
+	checkers.Issue{
+		Checker: "govet.Check", File: "a.go", Line: 3, Severity: checkers.SeverityError,
+		Message: "unreachable code", Raw: "a.go:3: unreachable code",
+	},
+	checkers.Issue{
+		Checker: "gostaticcheck.Check", File: "b.go", Line: 5, Column: 2, Severity: checkers.SeverityWarning,
+		RuleID: "SA4006", Message: "value never used", Raw: "b.go:5:2: value never used (SA4006)",
+	},
+	checkers.Issue{
+		Checker: "govet.Check", File: "a.go", Line: 8, Column: 1, Severity: checkers.SeverityError,
+		Message: `"x" & <y>`, Raw: `a.go:8:1: "x" & <y>`,
+	},
+	checkers.Issue{Checker: "dupl.Check", Message: "found 2 clones", Raw: "found 2 clones"},
+)
+
+const expectedText = `govet.Check: a.go:3: unreachable code
+gostaticcheck.Check: b.go:5:2: value never used (SA4006)
+govet.Check: a.go:8:1: "x" & <y>
+dupl.Check: found 2 clones
+`
+
+const expectedJSON = `{
+  "total": 4,
+  "checkers": {
+    "dupl.Check": 1,
+    "gostaticcheck.Check": 1,
+    "govet.Check": 2
+  },
+  "files": {
+    "a.go": 2,
+    "b.go": 1
+  },
+  "issues": [
+    {
+      "checker": "govet.Check",
+      "file": "a.go",
+      "line": 3,
+      "severity": "error",
+      "message": "unreachable code",
+      "raw": "a.go:3: unreachable code"
+    },
+    {
+      "checker": "gostaticcheck.Check",
+      "file": "b.go",
+      "line": 5,
+      "column": 2,
+      "severity": "warning",
+      "rule": "SA4006",
+      "message": "value never used",
+      "raw": "b.go:5:2: value never used (SA4006)"
+    },
+    {
+      "checker": "govet.Check",
+      "file": "a.go",
+      "line": 8,
+      "column": 1,
+      "severity": "error",
+      "message": "\"x\" & <y>",
+      "raw": "a.go:8:1: \"x\" & <y>"
+    },
+    {
+      "checker": "dupl.Check",
+      "message": "found 2 clones",
+      "raw": "found 2 clones"
+    }
+  ]
+}
+`
+
+const expectedCheckstyle = `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="5.0">
+  <file name="a.go">
+    <error line="3" severity="error" message="unreachable code" source="govet.Check"></error>
+    <error line="8" column="1" severity="error" message="&#34;x&#34; &amp; &lt;y&gt;" source="govet.Check"></error>
+  </file>
+  <file name="b.go">
+    <error line="5" column="2" severity="warning" message="value never used" source="gostaticcheck.Check.SA4006"></error>
+  </file>
+  <file name="">
+    <error line="0" severity="error" message="found 2 clones" source="dupl.Check"></error>
+  </file>
+</checkstyle>
+`
+
+const expectedJUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="3">
+  <testsuite name="govet.Check" tests="1" failures="1">
+    <testcase name="a.go" classname="govet.Check">
+      <failure message="2 issue(s)" type="error">a.go:3: unreachable code&#xA;a.go:8:1: &#34;x&#34; &amp; &lt;y&gt;&#xA;</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="gostaticcheck.Check" tests="1" failures="1">
+    <testcase name="b.go" classname="gostaticcheck.Check">
+      <failure message="1 issue(s)" type="warning">b.go:5:2: value never used (SA4006)&#xA;</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="dupl.Check" tests="1" failures="1">
+    <testcase name="dupl.Check" classname="dupl.Check">
+      <failure message="1 issue(s)" type="error">found 2 clones&#xA;</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		Format   string
+		Err      error
+		Expected string
+	}{
+		{Format: "text", Err: issues, Expected: expectedText},
+		{Format: "json", Err: issues, Expected: expectedJSON},
+		{Format: "checkstyle", Err: issues, Expected: expectedCheckstyle},
+		{Format: "junit", Err: issues, Expected: expectedJUnit},
+		{Format: "text", Err: nil, Expected: ""},
+		{
+			Format:   "json",
+			Err:      nil,
+			Expected: "{\n  \"total\": 0,\n  \"checkers\": {},\n  \"files\": {},\n  \"issues\": []\n}\n",
+		},
+		{
+			Format:   "checkstyle",
+			Err:      nil,
+			Expected: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<checkstyle version=\"5.0\"></checkstyle>\n",
+		},
+		{
+			Format:   "junit",
+			Err:      nil,
+			Expected: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<testsuites tests=\"0\" failures=\"0\"></testsuites>\n",
+		},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		if err := report.Formats[test.Format](&buf, test.Err); err != nil {
+			t.Error("Format", i, err)
+		} else if buf.String() != test.Expected {
+			t.Error("Format", i, "expected\n", test.Expected, "got\n", buf.String())
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "lint.json")
+	if err := report.WriteFile(path, report.Text, issues); err != nil {
+		t.Fatal(err)
+	}
+	if data, rerr := ioutil.ReadFile(path); rerr != nil || string(data) != expectedText {
+		t.Error("unexpected report", string(data), rerr)
+	}
+	if err := report.WriteFile(filepath.Join(dir, "missing", "lint.json"), report.Text, issues); err == nil {
+		t.Error("expected an error writing to a missing directory")
+	}
+}
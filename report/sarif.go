@@ -0,0 +1,177 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifSrcRoot = "%SRCROOT%"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+	HelpURI          string        `json:"helpUri,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string            `json:"ruleId"`
+	RuleIndex    int               `json:"ruleIndex"`
+	Level        string            `json:"level"`
+	Message      sarifMessage      `json:"message"`
+	Locations    []sarifLocation   `json:"locations,omitempty"`
+	Fingerprints map[string]string `json:"fingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// SARIF writes issues in the SARIF 2.1.0 format. A run is written for each
+// checker, using the checkers.Tool registered for it as the driver. Files are
+// located relative to the current directory, which is written as the
+// %SRCROOT% base URI. Each result has a fingerprint computed using
+// lint.Fingerprint.
+func SARIF(w io.Writer, err error) error {
+	wd, werr := os.Getwd()
+	if werr != nil {
+		return werr
+	}
+	root := map[string]sarifArtifactLocation{
+		sarifSrcRoot: {URI: fileURI(wd) + "/"},
+	}
+	log := sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{}}
+	for _, c := range groupBy(lint.Issues(err), byChecker) {
+		tool, _ := checkers.LookupTool(c.name)
+		run := sarifRun{
+			Tool:               sarifTool{Driver: sarifDriver{Name: tool.Name, InformationURI: tool.InformationURI}},
+			OriginalURIBaseIDs: root,
+		}
+		rules := map[string]int{}
+		for _, issue := range c.issues {
+			id := issue.RuleID
+			if id == "" {
+				id = tool.Name
+			}
+			index, ok := rules[id]
+			if !ok {
+				index, rules[id] = len(run.Tool.Driver.Rules), len(run.Tool.Driver.Rules)
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRuleFor(tool.Rule(id)))
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:       id,
+				RuleIndex:    index,
+				Level:        sarifLevel(issue.Severity),
+				Message:      sarifMessage{Text: issue.Message},
+				Locations:    sarifLocations(wd, issue),
+				Fingerprints: map[string]string{"lint/v1": lint.Fingerprint(issue)},
+			})
+		}
+		log.Runs = append(log.Runs, run)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+func sarifRuleFor(r checkers.Rule) sarifRule {
+	rule := sarifRule{ID: r.ID, HelpURI: r.HelpURI}
+	if r.Description != "" {
+		rule.ShortDescription = &sarifMessage{Text: r.Description}
+	}
+	return rule
+}
+
+func sarifLevel(s checkers.Severity) string {
+	switch s {
+	case checkers.SeverityWarning:
+		return "warning"
+	case checkers.SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+func sarifLocations(wd string, issue checkers.Issue) []sarifLocation {
+	if issue.File == "" {
+		return nil
+	}
+	loc := sarifPhysicalLocation{}
+	abs, err := filepath.Abs(issue.File)
+	if err != nil {
+		abs = issue.File
+	}
+	if rel, rerr := filepath.Rel(wd, abs); rerr == nil && !strings.HasPrefix(rel, "..") {
+		loc.ArtifactLocation = sarifArtifactLocation{
+			URI:       (&url.URL{Path: filepath.ToSlash(rel)}).String(),
+			URIBaseID: sarifSrcRoot,
+		}
+	} else {
+		loc.ArtifactLocation = sarifArtifactLocation{URI: fileURI(abs)}
+	}
+	if issue.Line > 0 {
+		loc.Region = &sarifRegion{StartLine: issue.Line, StartColumn: issue.Column}
+	}
+	return []sarifLocation{{PhysicalLocation: loc}}
+}
+
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,126 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+	_ "github.com/surullabs/lint/govet" // registers the vet tool
+	"github.com/surullabs/lint/report"
+)
+
+func TestSARIF(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	src := "package pkg\n\nfunc a() {\n\treturn\n\tb()\n}\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "pkg", "a.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	vet := checkers.ParseIssue("pkg/a.go:5: unreachable code")
+	vet.Checker = "govet.Check"
+	moved := vet
+	moved.Line = 6
+	custom := checkers.ParseIssue("/outside/b.go:2:3: bad thing (X100)")
+	custom.Checker, custom.Severity = "custom.Check", checkers.SeverityInfo
+
+	var buf bytes.Buffer
+	if err = report.SARIF(&buf, checkers.Issues(vet, custom, moved)); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name           string
+					InformationURI string
+					Rules          []map[string]interface{}
+				}
+			}
+			Results []struct {
+				RuleID       string
+				Level        string
+				Message      struct{ Text string }
+				Locations    []map[string]map[string]map[string]interface{}
+				Fingerprints map[string]string
+			}
+		}
+	}
+	if err = json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 2 {
+		t.Fatal("unexpected log", buf.String())
+	}
+
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "vet" || run.Tool.Driver.InformationURI != "https://golang.org/cmd/vet/" ||
+		len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0]["id"] != "vet" {
+		t.Error("unexpected govet tool", run.Tool)
+	}
+	if len(run.Results) != 2 || run.Results[0].RuleID != "vet" || run.Results[0].Level != "error" ||
+		run.Results[0].Message.Text != "unreachable code" {
+		t.Error("unexpected govet results", run.Results)
+	}
+	loc := run.Results[0].Locations[0]["physicalLocation"]
+	if !reflect.DeepEqual(loc["artifactLocation"], map[string]interface{}{"uri": "pkg/a.go", "uriBaseId": "%SRCROOT%"}) ||
+		!reflect.DeepEqual(loc["region"], map[string]interface{}{"startLine": 5.0}) {
+		t.Error("unexpected location", loc)
+	}
+	fp := run.Results[0].Fingerprints["lint/v1"]
+	if fp == "" || fp == run.Results[1].Fingerprints["lint/v1"] {
+		t.Error("unexpected fingerprints", run.Results)
+	}
+	// Moving the code does not change its fingerprint.
+	if err = ioutil.WriteFile(filepath.Join(dir, "pkg", "a.go"), []byte("\n"+src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if moved := lint.Fingerprint(moved); moved != fp {
+		t.Error("fingerprint changed after moving code", moved, fp)
+	}
+
+	run = log.Runs[1]
+	if run.Tool.Driver.Name != "custom.Check" || len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0]["id"] != "X100" {
+		t.Error("unexpected custom tool", run.Tool)
+	}
+	loc = run.Results[0].Locations[0]["physicalLocation"]
+	if run.Results[0].Level != "note" ||
+		!reflect.DeepEqual(loc["artifactLocation"], map[string]interface{}{"uri": "file:///outside/b.go"}) {
+		t.Error("unexpected custom result", run.Results)
+	}
+}
+
+func TestTool(t *testing.T) {
+	tool, ok := checkers.LookupTool("govet.Check")
+	if !ok || tool.Name != "vet" || tool.Rule("vet").Description == "" {
+		t.Error("govet tool not registered", tool)
+	}
+	tool = checkers.Tool{Name: "x", InformationURI: "https://x", RuleURI: "https://x/rules#%s"}
+	if r := tool.Rule("X1"); r.HelpURI != "https://x/rules#X1" {
+		t.Error("unexpected rule", r)
+	}
+	tool.RuleURI = ""
+	if r := tool.Rule("X1"); r.HelpURI != "https://x" {
+		t.Error("unexpected rule", r)
+	}
+	if tool, ok = checkers.LookupTool("unknown.Check"); ok || tool.Name != "unknown.Check" {
+		t.Error("unexpected tool", tool)
+	}
+}
@@ -0,0 +1,182 @@
+// Package rules checks project specific rules, such as banned imports, calls
+// and types, without running an external linter.
+//
+//    rules.Check{Rules: []rules.Rule{
+//        {
+//            ID:      "clock",
+//            Calls:   []string{"time.Now"},
+//            Except:  []string{"example.com/app/clock"},
+//            Message: "use clock.Now so that tests can control time",
+//        },
+//        {
+//            ID:      "http-client",
+//            Calls:   []string{"net/http.Get", "net/http.Post"},
+//            Message: "use example.com/app/client",
+//        },
+//    }}
+//
+// Rules can also be set in a configuration file, using the checker name rules.
+package rules
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/surullabs/lint/checkers"
+	"github.com/surullabs/lint/goanalysis"
+)
+
+// Rule bans the use of imports, functions, methods and types in a set of
+// packages.
+//
+// Packages are matched using import path patterns, as described in
+// checkers.MatchPackage. Functions are named by the package
+// path and name, such as net/http.Get, and methods by the receiver type and
+// name, such as (net/http.Client).Do. Types are named in the same way as
+// functions, such as sync.Mutex.
+type Rule struct {
+	// ID is reported as the RuleID of violations. It defaults to rules.
+	ID string
+	// Message is reported along with each violation, usually to describe
+	// what should be used instead.
+	Message string
+	// Imports lists the packages that must not be imported.
+	Imports []string
+	// Calls lists the functions and methods that must not be called or
+	// referenced.
+	Calls []string
+	// Types lists the types that must not be used.
+	Types []string
+	// Packages lists the packages the rule applies to. It applies to all
+	// packages if empty.
+	Packages []string
+	// Except lists packages the rule does not apply to.
+	Except []string
+}
+
+// Check implements lint.ContextChecker by checking Rules using goanalysis.
+type Check struct {
+	Rules []Rule
+	// Tags is a list of space separated build tags
+	Tags string
+	// Tests includes the test files of packages.
+	Tests bool
+}
+
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "rules",
+		InformationURI: "https://godoc.org/github.com/surullabs/lint/rules",
+	})
+	checkers.RegisterChecker("rules", Check{})
+}
+
+// Check reports violations of Rules in pkgs.
+func (c Check) Check(pkgs ...string) error {
+	return c.CheckContext(context.Background(), pkgs...)
+}
+
+// Version returns the version of goanalysis. It is part of the key used to
+// cache results.
+func (Check) Version() (string, error) { return goanalysis.Check{}.Version() }
+
+// CheckContext reports violations of Rules in pkgs, stopping if ctx is done.
+func (c Check) CheckContext(ctx context.Context, pkgs ...string) error {
+	if len(c.Rules) == 0 {
+		return nil
+	}
+	g := goanalysis.Check{Analyzers: []*analysis.Analyzer{c.Analyzer()}, Tags: c.Tags, Tests: c.Tests}
+	return g.CheckContext(ctx, pkgs...)
+}
+
+// Analyzer returns an Analyzer reporting violations of Rules, which can be run
+// by goanalysis.Check along with other analyzers. The category of each
+// diagnostic is the ID of the violated Rule.
+func (c Check) Analyzer() *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name: "rules",
+		Doc:  "reports violations of project specific rules",
+		Run:  c.run,
+	}
+}
+
+func (c Check) run(pass *analysis.Pass) (interface{}, error) {
+	for _, r := range c.Rules {
+		if r.appliesTo(pass.Pkg.Path()) {
+			r.check(pass)
+		}
+	}
+	return nil, nil
+}
+
+func (r Rule) appliesTo(pkg string) bool {
+	return (len(r.Packages) == 0 || matchAny(r.Packages, pkg)) && !matchAny(r.Except, pkg)
+}
+
+func (r Rule) check(pass *analysis.Pass) {
+	for _, f := range pass.Files {
+		for _, spec := range f.Imports {
+			if path, err := strconv.Unquote(spec.Path.Value); err == nil && matchAny(r.Imports, path) {
+				r.report(pass, spec, fmt.Sprintf("import of %s", path))
+			}
+		}
+	}
+	if len(r.Calls) == 0 && len(r.Types) == 0 {
+		return
+	}
+	for id, obj := range pass.TypesInfo.Uses {
+		switch o := obj.(type) {
+		case *types.Func:
+			if name := funcName(o); contains(r.Calls, name) {
+				r.report(pass, id, name)
+			}
+		case *types.TypeName:
+			if o.Pkg() != nil && contains(r.Types, o.Pkg().Path()+"."+o.Name()) {
+				r.report(pass, id, "type "+o.Pkg().Path()+"."+o.Name())
+			}
+		}
+	}
+}
+
+func (r Rule) report(pass *analysis.Pass, node ast.Node, what string) {
+	id := r.ID
+	if id == "" {
+		id = "rules"
+	}
+	msg := what + " is banned"
+	if r.Message != "" {
+		msg += ": " + r.Message
+	}
+	pass.Report(analysis.Diagnostic{Pos: node.Pos(), End: node.End(), Category: id, Message: msg})
+}
+
+// funcName returns the name of f as used in Rule.Calls. Pointer receivers are
+// named in the same way as value receivers.
+func funcName(f *types.Func) string {
+	return strings.Replace(f.Origin().FullName(), "*", "", 1)
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if strings.Replace(n, "*", "", 1) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny returns true if path matches any of patterns.
+func matchAny(patterns []string, path string) bool {
+	for _, p := range patterns {
+		if checkers.MatchPackage(p, path) {
+			return true
+		}
+	}
+	return false
+}
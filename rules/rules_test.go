@@ -0,0 +1,116 @@
+package rules_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/rules"
+	"github.com/surullabs/lint/testutil"
+)
+
+// issues returns a validator checking that err holds an issue for each of
+// expected, given as the rule ID followed by a suffix of the issue.
+func issues(expected ...string) func(err error) error {
+	return func(err error) error {
+		found := lint.Issues(err)
+		if len(found) != len(expected) {
+			return fmt.Errorf("expected %d issues, got %v", len(expected), found)
+		}
+		for i, e := range expected {
+			parts := strings.SplitN(e, " ", 2)
+			if found[i].RuleID != parts[0] || !strings.HasSuffix(found[i].Raw, parts[1]) {
+				return fmt.Errorf("expected %s, got %s %s", e, found[i].RuleID, found[i].Raw)
+			}
+		}
+		return nil
+	}
+}
+
+const src = `package rulestest
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var mu sync.Mutex
+
+func Get() (time.Time, error) {
+	var b strings.Builder
+	b.WriteString("http://example.com")
+	_, err := http.Get(b.String())
+	return time.Now(), err
+}
+`
+
+func TestRules(t *testing.T) {
+	testutil.Test(t, "rulestest", []testutil.StaticCheckTest{
+		{
+			Checker:  rules.Check{},
+			Content:  []byte(src),
+			Validate: testutil.NoError,
+		},
+		{
+			Checker: rules.Check{Rules: []rules.Rule{
+				{ID: "http", Imports: []string{"net/..."}, Message: "use the client package"},
+				{Types: []string{"sync.Mutex"}},
+				{ID: "clock", Calls: []string{"time.Now"}, Message: "use clock.Now"},
+				{ID: "builder", Calls: []string{"(*strings.Builder).WriteString"}},
+			}},
+			Content: []byte(src),
+			Validate: issues(
+				"http file.go:4:2: import of net/http is banned: use the client package",
+				"rules file.go:10:13: type sync.Mutex is banned",
+				"builder file.go:14:4: (strings.Builder).WriteString is banned",
+				"clock file.go:16:14: time.Now is banned: use clock.Now",
+			),
+		},
+		{
+			Checker: rules.Check{Rules: []rules.Rule{
+				{ID: "clock", Calls: []string{"time.Now"}, Except: []string{"rulestest"}},
+				{ID: "http", Calls: []string{"net/http.Get"}, Packages: []string{"other/..."}},
+				{ID: "get", Calls: []string{"net/http.Get"}, Packages: []string{"rulestest/..."}},
+			}},
+			Content:  []byte(src),
+			Validate: issues("get file.go:15:17: net/http.Get is banned"),
+		},
+	})
+}
+
+func TestConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lint.yaml")
+	config := `
+checkers:
+  - name: rules
+    options:
+      rules:
+        - id: clock
+          calls: [time.Now]
+          except: [example.com/app/clock]
+          message: use clock.Now
+`
+	if err := ioutil.WriteFile(file, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := lint.LoadConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := c.Group()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := lint.Group{rules.Check{Rules: []rules.Rule{
+		{ID: "clock", Calls: []string{"time.Now"}, Except: []string{"example.com/app/clock"}, Message: "use clock.Now"},
+	}}}
+	if !reflect.DeepEqual(g, expected) {
+		t.Errorf("expected %#v, got %#v", expected, g)
+	}
+}
@@ -13,6 +13,15 @@ type Skipper interface {
 	Skip(err string) bool
 }
 
+// IssueSkipper is a Skipper that can also skip structured issues.
+//
+// SkipIssue returns true if issue must be ignored. Skip uses SkipIssue instead
+// of Skip for errors that expose issues, as described in Issues.
+type IssueSkipper interface {
+	Skipper
+	SkipIssue(issue checkers.Issue) bool
+}
+
 // StringSkipper implements Skipper and skips an error if Matcher(err, str) == true for
 // any of Strings
 type StringSkipper struct {
@@ -30,6 +39,19 @@ func (s StringSkipper) Skip(check string) bool {
 	return false
 }
 
+func skipIssue(issue checkers.Issue, skippers []Skipper) bool {
+	for _, s := range skippers {
+		if is, ok := s.(IssueSkipper); ok {
+			if is.SkipIssue(issue) {
+				return true
+			}
+		} else if s.Skip(issue.String()) {
+			return true
+		}
+	}
+	return false
+}
+
 func skip(check string, skippers []Skipper) bool {
 	for _, s := range skippers {
 		if s.Skip(check) {
@@ -51,10 +73,23 @@ func skip(check string, skippers []Skipper) bool {
 //
 // Skippers are run in the order provided and a single
 // skipper returning true will result in that error being skipped.
+//
+// If err also exposes issues, as described in Issues, the skippers are applied to
+// the String form of each issue, or the issue itself for an IssueSkipper, and the
+// returned error exposes the remaining issues. Checkers that failed to run, as
+// returned by Failures, are kept even if their output is skipped.
 func Skip(err error, skippers ...Skipper) error {
 	switch serr := err.(type) {
 	case nil:
 		return nil
+	case issues:
+		var n []checkers.Issue
+		for _, issue := range serr.Issues() {
+			if !skipIssue(issue, skippers) {
+				n = append(n, issue)
+			}
+		}
+		return groupIssues(n, Failures(err))
 	case errors:
 		var n []string
 		errs := serr.Errors()
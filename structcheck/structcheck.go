@@ -1,7 +1,11 @@
 // Package structcheck provides lint integration for the structcheck linter
 package structcheck
 
-import "github.com/surullabs/lint/checkers"
+import (
+	"context"
+
+	"github.com/surullabs/lint/checkers"
+)
 
 // Check runs the structcheck linter (https://github.com/opennota/check)
 type Check struct {
@@ -13,13 +17,40 @@ type Check struct {
 	IncludeTests bool
 }
 
+// Binary is the pinned version of structcheck that is installed and run.
+var Binary = checkers.Binary{
+	Name:    "structcheck",
+	Package: "github.com/opennota/check/cmd/structcheck",
+	Module:  "github.com/opennota/check",
+	Version: "v0.0.0-20180911053232-0c771f5545ff",
+}
+
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "structcheck",
+		InformationURI: "https://github.com/opennota/check",
+		Rules: []checkers.Rule{
+			{ID: "structcheck", Description: "Unused struct fields", HelpURI: "https://github.com/opennota/check"},
+		},
+	})
+	checkers.RegisterChecker("structcheck", Check{})
+}
+
 // Check runs structcheck and returns any errors found.
 func (c Check) Check(pkgs ...string) error {
-	return checkers.Lint("structcheck",
-		"github.com/opennota/check",
-		"github.com/opennota/check/cmd/structcheck", pkgs, c.Args()...)
+	return c.CheckContext(context.Background(), pkgs...)
 }
 
+// CheckContext runs structcheck and returns any errors found. structcheck is
+// killed if ctx is done before it completes.
+func (c Check) CheckContext(ctx context.Context, pkgs ...string) error {
+	return checkers.LintBinary(ctx, Binary, pkgs, c.Args()...)
+}
+
+// Version returns the pinned version of structcheck. It is part of the key used to
+// cache results.
+func (Check) Version() (string, error) { return Binary.String(), nil }
+
 // Args returns command line flags for structcheck
 func (c Check) Args() []string {
 	var args []string
@@ -34,3 +65,6 @@ func (c Check) Args() []string {
 	}
 	return args
 }
+
+// Binaries returns the pinned version of structcheck. It is used by lint.Prefetch.
+func (Check) Binaries() []checkers.Binary { return []checkers.Binary{Binary} }
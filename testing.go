@@ -0,0 +1,66 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+// Test runs each Checker in g as a subtest of t, named after the type of the
+// Checker. Each issue is reported using t.Errorf, starting with the file and
+// line of the issue. A Checker whose linter cannot be installed is skipped
+// instead of failing.
+//
+//    func TestLint(t *testing.T) {
+//    	lint.Test(t, lint.Default, "./...")
+//    }
+//
+// A single Checker can then be run using go test -run TestLint/golint. Use
+// Check along with Skip or a Baseline if some issues must be ignored.
+//
+// Issues suppressed by directives are skipped as described in Group.Check.
+// Malformed and unused directives are reported by t once the subtests complete.
+func Test(t *testing.T, g Group, pkgs ...string) {
+	// Packages that cannot be loaded are reported by the checkers.
+	d, derr := loadDirectives(pkgs)
+	ran := []string{}
+	for _, checker := range g {
+		checker := checker
+		t.Run(CheckerName(checker), func(t *testing.T) {
+			err := check(context.Background(), checker, pkgs)
+			if ierr, ok := err.(*checkers.InstallError); ok {
+				t.Skipf("failed to install %s: %v", ierr.Bin, ierr)
+			}
+			if !failed(err) {
+				ran = append(ran, CheckerName(checker))
+			}
+			issues := namedIssues(checker, err)
+			if derr == nil {
+				issues = d.skip(issues)
+			}
+			for _, issue := range issues {
+				t.Errorf("%s", testMessage(issue))
+			}
+		})
+	}
+	if derr == nil {
+		for _, issue := range append(d.malformed, d.unused(ran)...) {
+			t.Errorf("%s", testMessage(issue))
+		}
+	}
+}
+
+// testMessage returns the raw issue, prefixed with its position if the linter
+// did not include it.
+func testMessage(issue checkers.Issue) string {
+	if issue.File == "" || strings.HasPrefix(issue.Raw, issue.File+":") {
+		return issue.Raw
+	}
+	if issue.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", issue.File, issue.Line, issue.Raw)
+	}
+	return fmt.Sprintf("%s: %s", issue.File, issue.Raw)
+}
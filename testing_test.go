@@ -0,0 +1,51 @@
+package lint_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/surullabs/lint"
+	"github.com/surullabs/lint/checkers"
+)
+
+// TestTestHelper is run by TestTest in a separate process, since the failures
+// it reports would otherwise fail this test.
+func TestTestHelper(t *testing.T) {
+	if os.Getenv("LINT_TEST_HELPER") != "1" {
+		t.Skip("only run by TestTest")
+	}
+	lint.Test(t, lint.Group{
+		checkFn(func(pkgs ...string) error {
+			return checkers.Issues(
+				checkers.ParseIssue("a.go:1:2: first in "+strings.Join(pkgs, ",")),
+				checkers.Issue{File: "b.go", Line: 3, Raw: "second"},
+			)
+		}),
+		ctxCheckFn(func(context.Context, ...string) error { return nil }),
+		lint.Timeout{Checker: checkFn(func(...string) error {
+			return &checkers.InstallError{Bin: "missing", Err: fmt.Errorf("not found")}
+		}), Duration: time.Minute},
+	}, "./pkg")
+}
+
+func TestTest(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestTestHelper$", "-test.v")
+	cmd.Env = append(os.Environ(), "LINT_TEST_HELPER=1")
+	out, err := cmd.CombinedOutput()
+	assert(t, err != nil, "expected failure")
+	for _, expected := range []string{
+		"--- FAIL: TestTestHelper/lint_test.checkFn ",
+		"a.go:1:2: first in ./pkg",
+		"b.go:3: second",
+		"--- PASS: TestTestHelper/lint_test.ctxCheckFn ",
+		"--- SKIP: TestTestHelper/lint_test.checkFn#01 ",
+		"failed to install missing: not found",
+	} {
+		assert(t, strings.Contains(string(out), expected), fmt.Sprintf("%q not found in:\n%s", expected, out))
+	}
+}
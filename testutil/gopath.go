@@ -0,0 +1,74 @@
+package testutil
+
+import (
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// SourceFile is a file created in a temporary GOPATH by NewGOPATH.
+type SourceFile struct {
+	// Src is a file to copy instead of using Content.
+	Src string
+	// Content is the content of the created file.
+	Content []byte
+	// Dest is the path of the file relative to the src directory of the GOPATH.
+	Dest string
+}
+
+// GOPATH is a temporary GOPATH created by NewGOPATH.
+type GOPATH struct {
+	// Path is the temporary directory prepended to GOPATH.
+	Path string
+
+	gopath, module string
+	hasModule      bool
+}
+
+// NewGOPATH creates files in a temporary directory and prepends it to GOPATH,
+// both in the environment and in build.Default. GO111MODULE is set to off, so
+// that go commands resolve packages in GOPATH even when run within a module.
+// Reset restores both variables and removes the directory.
+func NewGOPATH(prefix string, files []SourceFile) (*GOPATH, error) {
+	dir, err := ioutil.TempDir("", prefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		c := f.Content
+		if f.Src != "" {
+			if c, err = ioutil.ReadFile(f.Src); err != nil {
+				_ = os.RemoveAll(dir)
+				return nil, err
+			}
+		}
+		dst := filepath.Join(dir, "src", f.Dest)
+		if err = os.MkdirAll(filepath.Dir(dst), 0755); err == nil {
+			err = ioutil.WriteFile(dst, c, 0644)
+		}
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, err
+		}
+	}
+	g := &GOPATH{Path: dir, gopath: build.Default.GOPATH}
+	g.module, g.hasModule = os.LookupEnv("GO111MODULE")
+	gopath := dir + string(filepath.ListSeparator) + g.gopath
+	build.Default.GOPATH = gopath
+	_ = os.Setenv("GOPATH", gopath)
+	_ = os.Setenv("GO111MODULE", "off")
+	return g, nil
+}
+
+// Reset restores GOPATH and GO111MODULE and removes the temporary directory.
+func (g *GOPATH) Reset() {
+	build.Default.GOPATH = g.gopath
+	_ = os.Setenv("GOPATH", g.gopath)
+	if g.hasModule {
+		_ = os.Setenv("GO111MODULE", g.module)
+	} else {
+		_ = os.Unsetenv("GO111MODULE")
+	}
+	_ = os.RemoveAll(g.Path)
+}
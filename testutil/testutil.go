@@ -13,7 +13,6 @@ import (
 
 	"reflect"
 
-	"github.com/sridharv/fakegopath"
 	"github.com/surullabs/lint"
 	"github.com/surullabs/lint/checkers"
 )
@@ -32,16 +31,16 @@ type StaticCheckMultiFileTest struct {
 func (s StaticCheckMultiFileTest) Test(pkg string) error {
 	checkers.Unload(pkg)
 
-	files := make([]fakegopath.SourceFile, 0, len(s.Contents))
+	files := make([]SourceFile, 0, len(s.Contents))
 	for i, content := range s.Contents {
-		files = append(files, fakegopath.SourceFile{
+		files = append(files, SourceFile{
 			Src:     "",
 			Content: content,
 			Dest:    filepath.Join(pkg, "file"+strconv.Itoa(i)+".go"),
 		})
 	}
 
-	tmp, err := fakegopath.NewTemporaryWithFiles(pkg, files)
+	tmp, err := NewGOPATH(pkg, files)
 	if err != nil {
 		return fmt.Errorf("failed to create temporary go path: %v", err)
 	}
@@ -64,7 +63,7 @@ type StaticCheckTest struct {
 // Test runs the test for pkg.
 func (s StaticCheckTest) Test(pkg string) error {
 	checkers.Unload(pkg)
-	tmp, err := fakegopath.NewTemporaryWithFiles(pkg, []fakegopath.SourceFile{
+	tmp, err := NewGOPATH(pkg, []SourceFile{
 		{Src: s.File, Content: s.Content, Dest: filepath.Join(pkg, "file.go")},
 	})
 	if err != nil {
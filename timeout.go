@@ -0,0 +1,66 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/surullabs/lint/checkers"
+)
+
+// Timeout is a ContextChecker that stops Checker if it does not complete
+// within Duration.
+//
+// If Checker implements ContextChecker it is passed a context that is done when
+// the timeout expires. Checkers using checkers.LintContext kill the linter
+// along with any processes it started and report the package being checked.
+// Otherwise Timeout stops waiting for Checker and reports an error naming the
+// packages being checked, leaving Checker to complete in the background.
+//
+// Errors are reported with the type of Checker, as described in Group.Check.
+type Timeout struct {
+	Checker  Checker
+	Duration time.Duration
+}
+
+// Check runs Checker with a timeout of Duration.
+func (t Timeout) Check(pkgs ...string) error {
+	return t.CheckContext(context.Background(), pkgs...)
+}
+
+// CheckContext runs Checker with a timeout of Duration. Checker is also
+// stopped if ctx is done.
+func (t Timeout) CheckContext(ctx context.Context, pkgs ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, t.Duration)
+	defer cancel()
+	if _, ok := t.Checker.(ContextChecker); ok {
+		return t.result(check(ctx, t.Checker, pkgs))
+	}
+	done := make(chan error, 1)
+	go func() { done <- check(ctx, t.Checker, pkgs) }()
+	select {
+	case err := <-done:
+		return t.result(err)
+	case <-ctx.Done():
+		msg := fmt.Sprintf("stopped checking %s: %v", strings.Join(pkgs, " "), ctx.Err())
+		if ctx.Err() == context.DeadlineExceeded {
+			msg = fmt.Sprintf("timed out after %v checking %s", t.Duration, strings.Join(pkgs, " "))
+		}
+		return checkers.Issues(checkers.Issue{
+			Checker:  CheckerName(t.Checker),
+			Severity: checkers.SeverityError,
+			Message:  msg,
+			Raw:      msg,
+		})
+	}
+}
+
+// result returns the issues in err named after Checker. Failures are returned
+// unmodified so that they can be told apart from issues.
+func (t Timeout) result(err error) error {
+	if failed(err) {
+		return err
+	}
+	return checkers.Issues(namedIssues(t.Checker, err)...)
+}
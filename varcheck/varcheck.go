@@ -1,7 +1,11 @@
 // Package varcheck provides lint integration for the varcheck linter
 package varcheck
 
-import "github.com/surullabs/lint/checkers"
+import (
+	"context"
+
+	"github.com/surullabs/lint/checkers"
+)
 
 // Check runs the varcheck linter (https://github.com/opennota/check)
 type Check struct {
@@ -9,16 +13,40 @@ type Check struct {
 	ReportExported bool
 }
 
+// Binary is the pinned version of varcheck that is installed and run.
+var Binary = checkers.Binary{
+	Name:    "varcheck",
+	Package: "github.com/opennota/check/cmd/varcheck",
+	Module:  "github.com/opennota/check",
+	Version: "v0.0.0-20180911053232-0c771f5545ff",
+}
+
+func init() {
+	checkers.RegisterTool(Check{}, checkers.Tool{
+		Name:           "varcheck",
+		InformationURI: "https://github.com/opennota/check",
+		Rules: []checkers.Rule{
+			{ID: "varcheck", Description: "Unused variables and constants", HelpURI: "https://github.com/opennota/check"},
+		},
+	})
+	checkers.RegisterChecker("varcheck", Check{})
+}
+
 // Check runs varcheck and returns any errors found.
 func (c Check) Check(pkgs ...string) error {
-	if _, err := checkers.InstallMissing("varcheck", "github.com/opennota/check", "github.com/opennota/check/cmd/varcheck"); err != nil {
-		return err
-	}
-	return checkers.Lint("varcheck",
-		"github.com/opennota/check",
-		"github.com/opennota/check/cmd/varcheck", pkgs, c.Args()...)
+	return c.CheckContext(context.Background(), pkgs...)
+}
+
+// CheckContext runs varcheck and returns any errors found. varcheck is killed
+// if ctx is done before it completes.
+func (c Check) CheckContext(ctx context.Context, pkgs ...string) error {
+	return checkers.LintBinary(ctx, Binary, pkgs, c.Args()...)
 }
 
+// Version returns the pinned version of varcheck. It is part of the key used to
+// cache results.
+func (Check) Version() (string, error) { return Binary.String(), nil }
+
 // Args returns all args passed to varcheck
 func (c Check) Args() []string {
 	var args []string
@@ -27,3 +55,6 @@ func (c Check) Args() []string {
 	}
 	return args
 }
+
+// Binaries returns the pinned version of varcheck. It is used by lint.Prefetch.
+func (Check) Binaries() []checkers.Binary { return []checkers.Binary{Binary} }